@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// How long a blackjack game can wait for the player before standing automatically
+const BlackjackTimeout = time.Minute * 10
+
+// The house rules for blackjack, set by the owner of the casino
+type BlackjackRules struct {
+	Decks         int
+	HitSoft17     bool
+	BlackjackPays float64
+}
+
+// A game of blackjack. Cards are numbered 0 - 51.
+// The stake and the cover for the most the casino could pay out are held in escrow until the game is settled.
+type BlackjackGame struct {
+	User    string
+	Stake   int
+	Cover   int
+	Player  []int
+	Dealer  []int
+	Shoe    []int
+	Expires time.Time
+}
+
+var (
+	card_ranks = []string{"A", "2", "3", "4", "5", "6", "7", "8", "9", "10", "J", "Q", "K"}
+	card_suits = []string{"♠", "♥", "♦", "♣"}
+)
+
+// Formats a hand of cards, optionally hiding all but the first card
+func format_hand(hand []int, hide bool) string {
+	cards := []string{}
+	for i, card := range hand {
+		if hide && i > 0 {
+			cards = append(cards, "🂠")
+		} else {
+			cards = append(cards, card_ranks[card%13]+card_suits[card/13])
+		}
+	}
+	return strings.Join(cards, " ")
+}
+
+// Finds the value of a hand and whether it is soft (an ace is being counted as 11)
+func hand_value(hand []int) (int, bool) {
+	value, aces := 0, 0
+	for _, card := range hand {
+		rank := card%13 + 1
+		if rank == 1 {
+			aces += 1
+		}
+		if rank > 10 {
+			rank = 10
+		}
+		value += rank
+	}
+	if aces > 0 && value+10 <= 21 {
+		return value + 10, true
+	}
+	return value, false
+}
+
+// If a hand is a natural blackjack (21 from the first two cards)
+func is_blackjack(hand []int) bool {
+	value, _ := hand_value(hand)
+	return len(hand) == 2 && value == 21
+}
+
+// Draws a card from the shoe using the player's provably fair rolls
+func draw_card(game *BlackjackGame) int {
+	index, _ := casino_roll(game.User, "Blackjack", len(game.Shoe))
+	card := game.Shoe[index]
+	game.Shoe = append(game.Shoe[:index], game.Shoe[index+1:]...)
+	return card
+}
+
+// The largest amount the casino could have to pay out on a stake, which happens when the stake is doubled and won
+func blackjack_worst_case(stake int) int {
+	worst := stake * 2
+	if blackjack := int(float64(stake) * data.BlackjackRules.BlackjackPays); blackjack > worst {
+		worst = blackjack
+	}
+	return worst
+}
+
+// Starts a game of blackjack, reserving the stake and the worst case payout in escrow and dealing the cards.
+// Returns an error string, which is empty if the game started, and the id of the game.
+func start_blackjack(user string, stake int) (string, string) {
+	if err := check_gambling(user, stake); err != "" {
+		return err, ""
+	}
+	if err := reserve_bet(user, stake, blackjack_worst_case(stake)); err != "" {
+		return err, ""
+	}
+
+	game := &BlackjackGame{User: user, Stake: stake, Cover: blackjack_worst_case(stake), Shoe: make([]int, data.BlackjackRules.Decks*52), Expires: time.Now().Add(BlackjackTimeout)}
+	for i := range game.Shoe {
+		game.Shoe[i] = i % 52
+	}
+	game.Player = []int{draw_card(game)}
+	game.Dealer = []int{draw_card(game)}
+	game.Player = append(game.Player, draw_card(game))
+	game.Dealer = append(game.Dealer, draw_card(game))
+
+	id := fmt.Sprint(data.NextBlackjackGame)
+	data.NextBlackjackGame += 1
+	data.BlackjackGames[id] = game
+	return "", id
+}
+
+// Plays out the dealer's hand, pays out the stake and winnings and ends the game.
+// Returns a description of the result.
+func settle_blackjack(session *discordgo.Session, id string) string {
+	game := data.BlackjackGames[id]
+	delete(data.BlackjackGames, id)
+
+	player_account := data.PersonalAccounts[data.Users[game.User].PersonalAccount]
+	player, _ := hand_value(game.Player)
+
+	// The dealer only needs to draw if the player has not already bust or got blackjack
+	if player <= 21 && !is_blackjack(game.Player) {
+		for {
+			dealer, soft := hand_value(game.Dealer)
+			if dealer > 17 || (dealer == 17 && !(soft && data.BlackjackRules.HitSoft17)) {
+				break
+			}
+			game.Dealer = append(game.Dealer, draw_card(game))
+		}
+	}
+	dealer, _ := hand_value(game.Dealer)
+
+	winnings, push := 0, false
+	result := ""
+	switch {
+	case player > 21:
+		result = "You went bust."
+	case is_blackjack(game.Player) && is_blackjack(game.Dealer):
+		push = true
+		result = "You and the dealer both have blackjack, so your stake is returned."
+	case is_blackjack(game.Player):
+		winnings = int(float64(game.Stake) * data.BlackjackRules.BlackjackPays)
+		result = "Blackjack!"
+	case is_blackjack(game.Dealer):
+		result = "The dealer has blackjack."
+	case dealer > 21:
+		winnings = game.Stake
+		result = "The dealer went bust."
+	case player > dealer:
+		winnings = game.Stake
+		result = "You beat the dealer."
+	case player == dealer:
+		push = true
+		result = "It is a push, so your stake is returned."
+	default:
+		result = "The dealer wins."
+	}
+
+	payout := game.Stake + winnings
+	if winnings == 0 && !push {
+		payout = 0
+	}
+	tax, err := settle_bet(session, game.User, game.Stake, game.Cover, payout)
+	record_gambling_result(game.User, payout-game.Stake)
+	record_casino_bet(game.User, "Blackjack", game.Stake, payout, blackjack_edge())
+
+	if payout == 0 {
+		result += fmt.Sprint(" You have lost ", format_cheesecoins(game.Stake), ".")
+	} else if winnings > 0 {
+		notify_casino_win(session, player_account.Name, winnings, "blackjack")
+		result += fmt.Sprint(" You have won ", format_cheesecoins(winnings), format_winnings_tax(tax), ".")
+	}
+	if err != "" {
+		result += "\n\n" + err
+	}
+
+	return fmt.Sprint(format_blackjack(game, false), "\n\n", result)
+}
+
+// Formats the hands in a game of blackjack, optionally hiding the dealer's hole card
+func format_blackjack(game *BlackjackGame, hide bool) string {
+	player, _ := hand_value(game.Player)
+	dealer := "?"
+	if !hide {
+		value, _ := hand_value(game.Dealer)
+		dealer = fmt.Sprint(value)
+	}
+	return fmt.Sprint("Stake: ", format_cheesecoins(game.Stake), "\nDealer: ", format_hand(game.Dealer, hide), " (", dealer, ")\nYou: ", format_hand(game.Player, false), " (", player, ")")
+}
+
+// The buttons for the player's next move in a game of blackjack. Doubling is only allowed on the first two cards.
+func blackjack_buttons(id string) []discordgo.MessageComponent {
+	buttons := []discordgo.MessageComponent{
+		discordgo.Button{Label: "Hit", Style: discordgo.PrimaryButton, CustomID: "blackjack_hit:" + id},
+		discordgo.Button{Label: "Stand", Style: discordgo.SecondaryButton, CustomID: "blackjack_stand:" + id},
+	}
+	if len(data.BlackjackGames[id].Player) == 2 {
+		buttons = append(buttons, discordgo.Button{Label: "Double", Style: discordgo.SuccessButton, CustomID: "blackjack_double:" + id})
+	}
+	return buttons
+}
+
+// Check every minute for blackjack games the player has left, and stand for them
+func check_blackjack_games(session *discordgo.Session) {
+	for range time.Tick(time.Minute * 1) {
+		data_mutex.Lock()
+		for id, game := range data.BlackjackGames {
+			if time.Now().After(game.Expires) {
+				send_embed("Blackjack", session, game.User, "You did not play in time so you have stood.\n\n"+settle_blackjack(session, id), []*discordgo.MessageEmbedField{})
+			}
+		}
+		data_mutex.Unlock()
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// The longest title a campaign can have
+const MaxCampaignTitle = 100
+
+// A crowdfunding campaign for an organisation. Pledges are held in escrow until the deadline,
+// when they are all paid to the organisation if the goal has been reached or all refunded if it has not.
+type Campaign struct {
+	Organisation string
+	Title        string
+	Goal         int
+	Deadline     time.Time
+	Pledges      map[string]int
+	Milestone    int
+}
+
+// Finds the total pledged to a campaign
+func campaign_total(campaign *Campaign) int {
+	total := 0
+	for _, amount := range campaign.Pledges {
+		total += amount
+	}
+	return total
+}
+
+// Formats a campaign with its progress towards the goal
+func format_campaign(campaign *Campaign) string {
+	total := campaign_total(campaign)
+	return fmt.Sprint("**", campaign.Title, "** for ", data.OrganisationAccounts[campaign.Organisation].Name, "\n", format_cheesecoins(total), " of ", format_cheesecoins(campaign.Goal),
+		" pledged (", total*100/campaign.Goal, "%) by ", len(campaign.Pledges), " backer(s). Ends <t:", campaign.Deadline.Unix(), ":R>.")
+}
+
+// Sends a message about a campaign to its backers and the owners of the organisation
+func notify_campaign(session *discordgo.Session, campaign *Campaign, title string, description string) {
+	sent := map[string]bool{}
+	for user := range campaign.Pledges {
+		sent[user] = true
+		send_embed(title, session, user, description, []*discordgo.MessageEmbedField{})
+	}
+	for _, owner := range org_owners(data.OrganisationAccounts[campaign.Organisation]) {
+		if !sent[owner] {
+			send_embed(title, session, owner, description, []*discordgo.MessageEmbedField{})
+		}
+	}
+}
+
+// Tells the backers when a campaign passes another quarter of its goal
+func check_campaign_milestone(session *discordgo.Session, campaign *Campaign) {
+	milestone := campaign_total(campaign) * 4 / campaign.Goal
+	if milestone > 4 {
+		milestone = 4
+	}
+	if milestone > campaign.Milestone {
+		campaign.Milestone = milestone
+		notify_campaign(session, campaign, "Campaign progress", fmt.Sprint("The campaign has reached ", milestone*25, "% of its goal!\n\n", format_campaign(campaign)))
+	}
+}
+
+// Refunds every pledge to a campaign which has been removed
+func refund_campaign(session *discordgo.Session, campaign *Campaign, reason string) {
+	for user, amount := range campaign.Pledges {
+		data.Escrow.Balance -= amount
+		data.PersonalAccounts[data.Users[user].PersonalAccount].Balance += amount
+	}
+	notify_campaign(session, campaign, "Campaign refunded", fmt.Sprint(reason, "\n\n", format_campaign(campaign)))
+}
+
+// Settles a campaign once it has ended. If the goal was reached, every pledge is paid to the organisation as a payment from the backer, with sales tax as with /pay,
+// otherwise every pledge is refunded.
+func settle_campaign(session *discordgo.Session, id string) {
+	campaign := data.Campaigns[id]
+	delete(data.Campaigns, id)
+
+	organisation_account := data.OrganisationAccounts[campaign.Organisation]
+	total := campaign_total(campaign)
+	if total < campaign.Goal {
+		refund_campaign(session, campaign, "The campaign did not reach its goal, so every pledge has been refunded.")
+		return
+	}
+
+	taxes := 0
+	for user, amount := range campaign.Pledges {
+		_, _, tax, sales_tax := sales_transaction(amount, data.Escrow, organisation_account, data.PersonalAccounts[data.Users[user].PersonalAccount].Name, nil, nil)
+		taxes += tax + sales_tax
+	}
+	notify_campaign(session, campaign, "Campaign successful", fmt.Sprint("The campaign reached its goal! ", format_cheesecoins(total), " has been paid to ", organisation_account.Name, " (with ",
+		format_cheesecoins(taxes), " in tax).\n\n", format_campaign(campaign)))
+}
+
+// Check every minute for campaigns which have ended, and settle them
+func check_campaigns(session *discordgo.Session) {
+	for range time.Tick(time.Minute * 1) {
+		data_mutex.Lock()
+		for id, campaign := range data.Campaigns {
+			if time.Now().After(campaign.Deadline) {
+				settle_campaign(session, id)
+			}
+		}
+		data_mutex.Unlock()
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// The number of casino rolls kept so that players can verify them after the server seed is revealed
+const MaxCasinoRolls = 5000
+
+// The seeds used to generate a player's casino rolls.
+// Only the SHA-256 hash of the server seed is shown until it is rotated, so the casino cannot change it after seeing the client seed,
+// and the player cannot predict rolls from it. Each roll uses the next nonce.
+type CasinoSeed struct {
+	ServerSeed string
+	ClientSeed string
+	Nonce      int
+}
+
+// A roll made by the casino, kept so that it can be checked with /casino_verify
+type CasinoRoll struct {
+	User           string
+	Game           string
+	ServerSeedHash string
+	ClientSeed     string
+	Nonce          int
+	Sides          int
+	Result         int
+	Time           time.Time
+}
+
+// Generates a random hex string from the operating system's secure random number generator
+func random_seed(bytes int) string {
+	result := make([]byte, bytes)
+	if _, err := rand.Read(result); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(result)
+}
+
+// The SHA-256 hash of a server seed, which is published before the seed is used
+func hash_seed(server_seed string) string {
+	hash := sha256.Sum256([]byte(server_seed))
+	return hex.EncodeToString(hash[:])
+}
+
+// Finds the seeds of a user, generating them if the user has not gambled before
+func casino_seed(user string) *CasinoSeed {
+	seed, ok := data.CasinoSeeds[user]
+	if !ok {
+		seed = &CasinoSeed{ServerSeed: random_seed(32), ClientSeed: random_seed(8)}
+		data.CasinoSeeds[user] = seed
+	}
+	return seed
+}
+
+// Replaces the server seed of a user, optionally also setting a new client seed, and resets the nonce.
+// Returns the old seeds so that the server seed can be revealed.
+func rotate_casino_seed(user string, client_seed string) CasinoSeed {
+	seed := casino_seed(user)
+	old := *seed
+	if client_seed == "" {
+		client_seed = old.ClientSeed
+	}
+	*seed = CasinoSeed{ServerSeed: random_seed(32), ClientSeed: client_seed}
+	return old
+}
+
+// Derives a roll between 0 and sides - 1 from the seeds and nonce.
+// Each HMAC-SHA256 of "client_seed:nonce:round" keyed by the server seed is split into 4 byte numbers,
+// and numbers which would make some results more likely than others are skipped.
+func fair_roll(server_seed string, client_seed string, nonce int, sides int) int {
+	limit := uint64(1<<32) - uint64(1<<32)%uint64(sides)
+	for round := 0; ; round++ {
+		mac := hmac.New(sha256.New, []byte(server_seed))
+		mac.Write([]byte(fmt.Sprint(client_seed, ":", nonce, ":", round)))
+		sum := mac.Sum(nil)
+		for i := 0; i+4 <= len(sum); i += 4 {
+			value := uint64(binary.BigEndian.Uint32(sum[i : i+4]))
+			if value < limit {
+				return int(value % uint64(sides))
+			}
+		}
+	}
+}
+
+// Makes a provably fair roll between 0 and sides - 1 for a user, using up their next nonce, and records it.
+// Returns the result and the nonce used.
+func casino_roll(user string, game string, sides int) (int, int) {
+	seed := casino_seed(user)
+	nonce := seed.Nonce
+	seed.Nonce += 1
+
+	result := fair_roll(seed.ServerSeed, seed.ClientSeed, nonce, sides)
+
+	data.CasinoRolls = append(data.CasinoRolls, &CasinoRoll{User: user, Game: game, ServerSeedHash: hash_seed(seed.ServerSeed), ClientSeed: seed.ClientSeed, Nonce: nonce, Sides: sides, Result: result, Time: time.Now()})
+	if len(data.CasinoRolls) > MaxCasinoRolls {
+		data.CasinoRolls = data.CasinoRolls[len(data.CasinoRolls)-MaxCasinoRolls:]
+	}
+	return result, nonce
+}
+
+// Finds the recorded roll made with the seeds and nonce, or nil if there is none
+func find_casino_roll(server_seed_hash string, client_seed string, nonce int) *CasinoRoll {
+	for _, roll := range data.CasinoRolls {
+		if roll.ServerSeedHash == server_seed_hash && roll.ClientSeed == client_seed && roll.Nonce == nonce {
+			return roll
+		}
+	}
+	return nil
+}
+
+// Formats who a roll was made for, which is the casino itself for rolls that are not made with a player's seeds such as lottery draws
+func format_roll_user(roll *CasinoRoll) string {
+	if _, ok := data.Users[roll.User]; !ok {
+		return "the " + strings.ToLower(roll.Game)
+	}
+	return format_user(roll.User)
+}
+
+// How long it takes for a raised or removed daily loss limit to apply, so that it cannot be raised in the heat of the moment
+const LossLimitDelay = time.Hour * 24
+
+// Applies a raised daily loss limit once it has waited long enough
+func apply_pending_loss_limit(usr *User) {
+	if !usr.PendingLossLimitTime.IsZero() && time.Now().After(usr.PendingLossLimitTime) {
+		usr.LossLimit = usr.PendingLossLimit
+		usr.PendingLossLimit = 0
+		usr.PendingLossLimitTime = time.Time{}
+	}
+}
+
+// Finds how much a user has lost at the casino today, after taking away their winnings
+func gambling_loss_today(usr *User) int {
+	if !same_day(usr.GamblingLossDay, time.Now()) {
+		return 0
+	}
+	return usr.GamblingLoss
+}
+
+// Checks that a bet of this size would not take a user past their daily loss limit.
+// Returns an error string, which is empty if the bet is allowed.
+func check_loss_limit(user string, stake int) string {
+	usr := data.Users[user]
+	apply_pending_loss_limit(usr)
+	if usr.LossLimit > 0 && gambling_loss_today(usr)+stake > usr.LossLimit {
+		return fmt.Sprint("**ERROR:** This bet could take you past your daily loss limit of ", format_cheesecoins(usr.LossLimit), ". You have lost ", format_cheesecoins(gambling_loss_today(usr)), " today.")
+	}
+	return ""
+}
+
+// Checks that a user may place a bet at the casino: they must not have excluded themselves, have an overdue loan (if the bank forbids it),
+// have bet too recently or risk going past their daily loss limit. Should be called before every casino game, which then records the time of the bet.
+// Returns an error string, which is empty if the bet is allowed.
+func check_gambling(user string, stake int) string {
+	if err := check_gambling_allowed(user, stake); err != "" {
+		return err
+	}
+	if next_bet := data.Users[user].LastBet.Add(time.Second * time.Duration(data.BetInterval)); time.Now().Before(next_bet) {
+		return fmt.Sprint("**ERROR:** You are betting too quickly. You can bet again <t:", next_bet.Unix(), ":R>.")
+	}
+	return ""
+}
+
+// Checks everything check_gambling does except the time since the last bet, for raising a bet in a game which has already started.
+// Returns an error string, which is empty if the bet is allowed.
+func check_gambling_allowed(user string, stake int) string {
+	usr := data.Users[user]
+	if time.Now().Before(usr.SelfExcluded) {
+		return fmt.Sprint("**ERROR:** You have excluded yourself from the casino until <t:", usr.SelfExcluded.Unix(), ":f>.")
+	}
+	if data.BlockOverdueGambling {
+		for _, loan := range data.PersonalAccounts[usr.PersonalAccount].Loans {
+			if loan.Overdue {
+				return "**ERROR:** The bank does not allow people with overdue loans to gamble. Repay your loan first."
+			}
+		}
+	}
+	return check_loss_limit(user, stake)
+}
+
+// Records the result of a bet towards a user's daily loss, where a negative result is a loss
+func record_gambling_result(user string, result int) {
+	usr := data.Users[user]
+	usr.GamblingLoss = gambling_loss_today(usr) - result
+	usr.GamblingLossDay = time.Now()
+}
+
+// Places a bet by holding the player's stake and the most the casino could have to pay out in escrow,
+// so that payments made while the bet is being resolved cannot leave either of them short.
+// Returns an error string, which is empty if the bet was placed.
+func reserve_bet(user string, stake int, cover int) string {
+	player_account := data.PersonalAccounts[data.Users[user].PersonalAccount]
+	casino_account := data.OrganisationAccounts[casino]
+	if stake > player_account.Balance {
+		return "**ERROR:** You do not have enough funds."
+	}
+	if cover > casino_account.Balance {
+		return "**ERROR:** The casino does not have enough funds."
+	}
+
+	player_account.Balance -= stake
+	casino_account.Balance -= cover
+	data.Escrow.Balance += stake + cover
+	data.Users[user].LastBet = time.Now()
+	return ""
+}
+
+// Settles a bet held in escrow, where the payout is everything returned to the player including their stake.
+// The player's stake and the casino's unused cover are returned to them, and the rest goes through the ledger:
+// winnings are paid from the cover taxed at the winnings tax, and a lost stake is paid to the casino taxed at the transaction tax.
+// Returns the winnings tax and an error string, which is empty if the bet was settled in full.
+func settle_bet(session *discordgo.Session, user string, stake int, cover int, payout int) (int, string) {
+	player_account := data.PersonalAccounts[data.Users[user].PersonalAccount]
+	casino_account := data.OrganisationAccounts[casino]
+
+	err := ""
+	winnings := payout - stake
+	if winnings > cover {
+		err = fmt.Sprint("**ERROR:** The casino only held ", format_cheesecoins(cover), " to cover your winnings of ", format_cheesecoins(winnings), ". Contact the casino owner for the rest.")
+		winnings = cover
+	}
+
+	// Return the stake unless it was lost
+	refund := stake
+	if payout < stake {
+		refund = payout
+	}
+	data.Escrow.Balance -= refund
+	player_account.Balance += refund
+
+	if winnings < 0 {
+		winnings = 0
+	}
+	data.Escrow.Balance -= cover - winnings
+	casino_account.Balance += cover - winnings
+
+	tax := 0
+	if winnings > 0 {
+		_, _, tax, _ = winnings_transaction(winnings, data.Escrow, player_account, casino_account.Name, nil, nil)
+	}
+
+	if lost := stake - refund; lost > 0 {
+		transaction(lost, data.Escrow, casino_account, player_account.Name, session, nil)
+	}
+	return tax, err
+}
+
+// Formats the winnings tax paid on a win, if there was any
+func format_winnings_tax(tax int) string {
+	if tax == 0 {
+		return ""
+	}
+	return fmt.Sprint(" (with ", format_cheesecoins(tax), " in winnings tax)")
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// How long settled bets are kept for reports
+const CasinoBetHistory = time.Hour * 24 * 365
+
+// A settled bet at the casino. Payout is everything returned to the player, including their stake,
+// and Edge is the share of the stake the casino expected to keep under the rules at the time.
+type CasinoBet struct {
+	User   string
+	Game   string
+	Stake  int
+	Payout int
+	Edge   float64
+	Time   time.Time
+}
+
+// Totals of the bets on one game, or on all games
+type CasinoTotals struct {
+	Bets        int
+	Stakes      int
+	Payouts     int
+	Theoretical float64
+}
+
+// Records a settled bet and forgets bets too old to be reported on
+func record_casino_bet(user string, game string, stake int, payout int, edge float64) {
+	data.CasinoBets = append(data.CasinoBets, &CasinoBet{User: user, Game: game, Stake: stake, Payout: payout, Edge: edge, Time: time.Now()})
+
+	for len(data.CasinoBets) > 0 && time.Since(data.CasinoBets[0].Time) > CasinoBetHistory {
+		data.CasinoBets = data.CasinoBets[1:]
+	}
+}
+
+// The house edge on a dice bet, where the player wins their stake times the returns minus one with a chance of 1 in 6
+func dice_edge() float64 {
+	return 1 - data.CasinoReturns/6
+}
+
+// The house edge on a set of roulette bets, weighted by their stakes
+func roulette_edge(bets []*RouletteBet) float64 {
+	stakes, expected := 0, 0.0
+	for _, bet := range bets {
+		stakes += bet.Stake
+		for number := 0; number <= 36; number++ {
+			if roulette_wins(bet, number) {
+				expected += float64(bet.Stake+roulette_winnings(bet)) / 37
+			}
+		}
+	}
+	return 1 - expected/float64(stakes)
+}
+
+// An approximate house edge for blackjack played with basic strategy under the current rules
+func blackjack_edge() float64 {
+	edge := 0.005 + (1.5-data.BlackjackRules.BlackjackPays)*0.045
+	if data.BlackjackRules.HitSoft17 {
+		edge += 0.002
+	}
+	if data.BlackjackRules.Decks == 1 {
+		edge -= 0.005
+	}
+	return edge
+}
+
+// Sends a player's win to the owners of the casino, except those who only want the daily digest
+func notify_casino_win(session *discordgo.Session, player string, winnings int, game string) {
+	for _, owner := range org_owners(data.OrganisationAccounts[casino]) {
+		if !data.Users[owner].CasinoDigest {
+			send_embed("Casino payout", session, owner, fmt.Sprint(player, " has won ", format_cheesecoins(winnings), " at ", game, " in your casino."), []*discordgo.MessageEmbedField{})
+		}
+	}
+}
+
+// Formats a share of the stakes as a percentage, or a dash if nothing was staked
+func format_edge(edge float64, stakes int) string {
+	if stakes == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f%%", edge*100)
+}
+
+// Formats the bets taken, payouts, gross gaming revenue (stakes minus payouts) and actual and theoretical house edge of the casino
+// since a time, broken down by game, along with the largest wins
+func casino_report(since time.Time) string {
+	total := &CasinoTotals{}
+	games := map[string]*CasinoTotals{}
+	wins := []*CasinoBet{}
+	for _, bet := range data.CasinoBets {
+		if bet.Time.Before(since) {
+			continue
+		}
+		if _, ok := games[bet.Game]; !ok {
+			games[bet.Game] = &CasinoTotals{}
+		}
+		for _, totals := range []*CasinoTotals{total, games[bet.Game]} {
+			totals.Bets += 1
+			totals.Stakes += bet.Stake
+			totals.Payouts += bet.Payout
+			totals.Theoretical += float64(bet.Stake) * bet.Edge
+		}
+		if bet.Payout > bet.Stake {
+			wins = append(wins, bet)
+		}
+	}
+
+	revenue := total.Stakes - total.Payouts
+	result := fmt.Sprintf("```\n%-22s %d\n%-22s %s\n%-22s %s\n%-22s %s\n%-22s %s\n%-22s %s\n```",
+		"Bets:", total.Bets, "Stakes:", format_cheesecoins(total.Stakes), "Payouts:", format_cheesecoins(total.Payouts), "Gross gaming revenue:", format_cheesecoins(revenue),
+		"Actual edge:", format_edge(float64(revenue)/float64(total.Stakes), total.Stakes), "Theoretical edge:", format_edge(total.Theoretical/float64(total.Stakes), total.Stakes))
+
+	if len(games) > 0 {
+		names := []string{}
+		for name := range games {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		result += fmt.Sprintf("\n**By game**\n```\n%-10s %5s %12s %12s %8s %8s", "", "Bets", "Stakes", "Revenue", "Actual", "Theory")
+		for _, name := range names {
+			totals := games[name]
+			revenue := totals.Stakes - totals.Payouts
+			result += fmt.Sprintf("\n%-10s %5d %12s %12s %8s %8s", name, totals.Bets, format_cheesecoins(totals.Stakes), format_cheesecoins(revenue),
+				format_edge(float64(revenue)/float64(totals.Stakes), totals.Stakes), format_edge(totals.Theoretical/float64(totals.Stakes), totals.Stakes))
+		}
+		result += "\n```"
+	}
+
+	// The lottery is recorded as one bet for the whole round, which the casino never loses, so every win is by a player
+	if len(wins) > 0 {
+		sort.Slice(wins, func(i, j int) bool { return wins[i].Payout-wins[i].Stake > wins[j].Payout-wins[j].Stake })
+		if len(wins) > 5 {
+			wins = wins[:5]
+		}
+		result += "\n**Largest wins**"
+		for _, win := range wins {
+			result += fmt.Sprint("\n", format_user(win.User), " won ", format_cheesecoins(win.Payout-win.Stake), " at ", win.Game, " <t:", win.Time.Unix(), ":R>")
+		}
+	}
+
+	return result
+}
+
+// Check every minute if a new day has started, and if so send the report for the last day to the casino owners who want a daily digest
+func check_casino_digest(session *discordgo.Session) {
+	for range time.Tick(time.Minute * 1) {
+		data_mutex.Lock()
+		if !same_day(data.LastCasinoDigest, time.Now()) {
+			data.LastCasinoDigest = time.Now()
+			report := casino_report(time.Now().AddDate(0, 0, -1))
+			for _, owner := range org_owners(data.OrganisationAccounts[casino]) {
+				if data.Users[owner].CasinoDigest {
+					send_embed("Daily casino report", session, owner, report, []*discordgo.MessageEmbedField{})
+				}
+			}
+		}
+		data_mutex.Unlock()
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"testing"
+)
+
+func TestFairRollIsDeterministic(t *testing.T) {
+	for nonce := 0; nonce < 100; nonce++ {
+		first := fair_roll("server seed", "client seed", nonce, 37)
+		second := fair_roll("server seed", "client seed", nonce, 37)
+		if first != second {
+			t.Fatalf("nonce %d rolled %d then %d with the same seeds", nonce, first, second)
+		}
+	}
+}
+
+func TestFairRollIsInRange(t *testing.T) {
+	for _, sides := range []int{2, 6, 37, 1000, 3 << 30} {
+		for nonce := 0; nonce < 2000; nonce++ {
+			if result := fair_roll("server seed", "client seed", nonce, sides); result < 0 || result >= sides {
+				t.Fatalf("nonce %d rolled %d with %d sides", nonce, result, sides)
+			}
+		}
+	}
+}
+
+// Compares the counts of each result against a uniform distribution with Pearson's chi-square test.
+// The seeds are fixed, so the test always gives the same answer.
+func TestFairRollIsUniform(t *testing.T) {
+	// Critical values of the chi-square distribution at p = 0.001 for sides - 1 degrees of freedom
+	critical := map[int]float64{6: 20.515, 37: 67.985}
+
+	for sides, limit := range critical {
+		rolls := sides * 10000
+		counts := make([]int, sides)
+		for nonce := 0; nonce < rolls; nonce++ {
+			counts[fair_roll("uniformity server seed", "uniformity client seed", nonce, sides)]++
+		}
+
+		expected := float64(rolls) / float64(sides)
+		chi_square := 0.0
+		for _, count := range counts {
+			chi_square += (float64(count) - expected) * (float64(count) - expected) / expected
+		}
+		if chi_square > limit {
+			t.Errorf("%d sides gave chi-square %.2f, above %.3f: %v", sides, chi_square, limit, counts)
+		}
+	}
+}
+
+// With 3 * 2^30 sides, every 4 byte number of 3 * 2^30 or more would make the low results more likely, so it must be skipped
+// in favour of the next number. Recomputes the numbers to check the result always comes from the first one below the limit.
+func TestFairRollRejectsBiasedRange(t *testing.T) {
+	sides := 3 << 30
+	limit := uint64(1<<32) - uint64(1<<32)%uint64(sides)
+	if limit != uint64(sides) {
+		t.Fatalf("limit is %d, expected %d", limit, sides)
+	}
+
+	rejected := 0
+	for nonce := 0; nonce < 1000; nonce++ {
+		values := []uint64{}
+		for round := 0; len(values) == 0 || values[len(values)-1] >= limit; round++ {
+			mac := hmac.New(sha256.New, []byte("server seed"))
+			mac.Write([]byte(fmt.Sprint("client seed:", nonce, ":", round)))
+			sum := mac.Sum(nil)
+			for i := 0; i+4 <= len(sum) && (len(values) == 0 || values[len(values)-1] >= limit); i += 4 {
+				values = append(values, uint64(binary.BigEndian.Uint32(sum[i:i+4])))
+			}
+		}
+		rejected += len(values) - 1
+
+		expected := int(values[len(values)-1] % uint64(sides))
+		if result := fair_roll("server seed", "client seed", nonce, sides); result != expected {
+			t.Fatalf("nonce %d rolled %d, expected %d from the first number below the limit", nonce, result, expected)
+		}
+	}
+
+	// A quarter of the numbers are above the limit, so some must have been skipped
+	if rejected == 0 {
+		t.Fatal("no numbers were skipped")
+	}
+}
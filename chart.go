@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+)
+
+const (
+	chart_width   = 800
+	chart_height  = 400
+	chart_padding = 20
+)
+
+var (
+	chart_background = color.RGBA{0x2F, 0x31, 0x36, 0xFF}
+	chart_grid       = color.RGBA{0x4F, 0x54, 0x5C, 0xFF}
+	chart_line       = color.RGBA{0xFF, 0xE4, 0x1E, 0xFF}
+)
+
+// Draws a line of the given thickness between two points using Bresenham's algorithm
+func draw_line(img *image.RGBA, x0 int, y0 int, x1 int, y1 int, c color.Color, thickness int) {
+	dx := int(math.Abs(float64(x1 - x0)))
+	dy := -int(math.Abs(float64(y1 - y0)))
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+
+	for {
+		for ox := 0; ox < thickness; ox++ {
+			for oy := 0; oy < thickness; oy++ {
+				img.Set(x0+ox-thickness/2, y0+oy-thickness/2, c)
+			}
+		}
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// Renders a line chart of the values as a PNG image.
+// The image has no text, so the caller should describe the scale (see `chart_range`).
+func render_chart(values []float64) (*bytes.Buffer, error) {
+	img := image.NewRGBA(image.Rect(0, 0, chart_width, chart_height))
+	for x := 0; x < chart_width; x++ {
+		for y := 0; y < chart_height; y++ {
+			img.Set(x, y, chart_background)
+		}
+	}
+
+	// Horizontal grid lines at each quarter of the range
+	for i := 0; i <= 4; i++ {
+		y := chart_padding + i*(chart_height-2*chart_padding)/4
+		draw_line(img, chart_padding, y, chart_width-chart_padding, y, chart_grid, 1)
+	}
+
+	min, max := chart_range(values)
+	point := func(i int) (int, int) {
+		x := chart_padding
+		if len(values) > 1 {
+			x += i * (chart_width - 2*chart_padding) / (len(values) - 1)
+		}
+		y := chart_height - chart_padding - int((values[i]-min)/(max-min)*float64(chart_height-2*chart_padding))
+		return x, y
+	}
+
+	for i := 1; i < len(values); i++ {
+		x0, y0 := point(i - 1)
+		x1, y1 := point(i)
+		draw_line(img, x0, y0, x1, y1, chart_line, 3)
+	}
+	if len(values) == 1 {
+		x, y := point(0)
+		draw_line(img, x, y, chart_width-chart_padding, y, chart_line, 3)
+	}
+
+	result := &bytes.Buffer{}
+	err := png.Encode(result, img)
+	return result, err
+}
+
+// The minimum and maximum of the chart's vertical axis, which are never equal
+func chart_range(values []float64) (float64, float64) {
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	if len(values) == 0 {
+		return 0, 1
+	}
+	if min == max {
+		return min - 1, max + 1
+	}
+	return min, max
+}
@@ -4,13 +4,19 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"math"
-	"math/rand"
 	"os"
 	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/sahilm/fuzzy"
@@ -31,27 +37,198 @@ type User struct {
 	Mp                bool
 	LastPay           time.Time
 	PayStreak         int
+	LastActive        time.Time
 	Organisations     []string
+
+	// Responsible gambling
+	LossLimit            int
+	PendingLossLimit     int
+	PendingLossLimitTime time.Time
+	GamblingLoss         int
+	GamblingLossDay      time.Time
+	LastBet              time.Time
+	SelfExcluded         time.Time
+
+	// Casino owners who only want a daily report instead of a message for each win
+	CasinoDigest bool
+}
+
+// A change to a rate. Time is when the change was made and Effective is when it applies, which is later for scheduled changes.
+type RateChange struct {
+	Rate      string
+	OldValue  float64
+	NewValue  float64
+	User      string
+	Time      time.Time
+	Effective time.Time
+}
+
+// Statistics about the economy, recorded once per day
+type EconomySnapshot struct {
+	Time              time.Time
+	MoneySupply       int
+	Treasury          int
+	TaxCollected      int
+	TransactionVolume int
+	Transactions      int
+	ActiveAccounts    int
+	OutstandingLoans  int
+	Gini              float64
+	// The balance of every account, which is only kept for MaxChartDays as it grows with the number of accounts
+	Balances map[string]int
+}
+
+// The longest period that can be charted, in days
+const MaxChartDays = 365
+
+// A statistic that can be read from an economy snapshot
+type EconomyMetric struct {
+	Id    string
+	Name  string
+	Value func(*EconomySnapshot) float64
+}
+
+// An offer to transfer an organisation to a new owner, which they must accept
+type TransferOffer struct {
+	Organisation string
+	From         string
+	To           string
+	Expires      time.Time
+	Confirmed    bool
+
+	// Set when the shareholders voted for the new owner, in which case there is no From and every current owner becomes a viewer
+	ShareholderVote bool
+}
+
+// Limits on how much a member of an organisation can pay from its account. Zero means no limit.
+type SpendingLimit struct {
+	Daily          int
+	PerTransaction int
+	Spent          int
+	SpentOn        time.Time
+}
+
+// A payment from an organisation that is waiting for another member to approve it
+type PendingPayment struct {
+	Organisation string
+	Recipiant    string
+	Amount       int
+	Requester    string
+	Expires      time.Time
+}
+
+// An employee on the payroll of an organisation
+type Employee struct {
+	Salary   int
+	Interval int
+	LastPaid time.Time
 }
 
 type Account struct {
-	Name    string
-	Balance int
-	Loans   []*Loan
+	Name              string
+	Balance           int
+	Loans             []*Loan
+	Members           map[string]int
+	Limits            map[string]*SpendingLimit
+	ApprovalThreshold int
+	Payroll           map[string]*Employee
+	PayrollFailed     time.Time
+	Shares            map[string]int
+	Votes             map[string]string
+	Description       string
+	Category          string
+	LogoUrl           string
+	Founded           time.Time
+	PublicBalance     bool
+	Archived          time.Time
 }
 
 type Data struct {
-	Users                map[string]*User
-	PersonalAccounts     map[string]*Account
-	OrganisationAccounts map[string]*Account
-	NextPersonal         int
-	NextOrg              int
-	TransactionTax       float64
-	WealthTax            float64
-	LastWealthTax        time.Time
-	BankHolidays         []int64
-	LoanInterest         float64
-	CasinoReturns        float64
+	Users                 map[string]*User
+	PersonalAccounts      map[string]*Account
+	OrganisationAccounts  map[string]*Account
+	NextPersonal          int
+	NextOrg               int
+	TransactionTax        float64
+	SalesTax              float64
+	WealthTax             float64
+	LastWealthTax         time.Time
+	BankHolidays          []int64
+	LoanInterest          float64
+	CasinoReturns         float64
+	UbiAmount             int
+	UbiInterval           int
+	UbiActiveDays         int
+	LastUbi               time.Time
+	RateHistory           []*RateChange
+	ScheduledRates        []*RateChange
+	Snapshots             []*EconomySnapshot
+	DayTaxCollected       int
+	DayTransactionVolume  int
+	DayTransactions       int
+	TransferOffers        map[string]*TransferOffer
+	NextTransferOffer     int
+	PendingPayments       map[string]*PendingPayment
+	NextPendingPayment    int
+	Escrow                *Account
+	Orders                []*Order
+	Trades                []*Trade
+	NextOrder             int
+	OrgCreationFee        int
+	ArchivedOrganisations map[string]*Account
+	CasinoSeeds           map[string]*CasinoSeed
+	CasinoRolls           []*CasinoRoll
+	BlackjackRules        *BlackjackRules
+	BlackjackGames        map[string]*BlackjackGame
+	NextBlackjackGame     int
+	RouletteHouseEdge     float64
+	LotteryRounds         []*LotteryRound
+	LotteryTax            float64
+	BetInterval           int
+	BlockOverdueGambling  bool
+	CasinoBets            []*CasinoBet
+	LastCasinoDigest      time.Time
+	WinningsTax           float64
+	Wagers                map[string]*Wager
+	NextWager             int
+	EscrowPayments        map[string]*EscrowPayment
+	NextEscrowPayment     int
+	Campaigns             map[string]*Campaign
+	NextCampaign          int
+}
+
+// Names of the rates which can be changed by the rate commands
+const (
+	RateWealthTax      = "Wealth Tax"
+	RateTransactionTax = "Transaction Tax"
+	RateSalesTax       = "Sales Tax"
+	RateInterest       = "Interest Rate"
+	RateLotteryTax     = "Lottery Tax"
+	RateWinningsTax    = "Winnings Tax"
+)
+
+// Roles of the members of an organisation. Each role has the permissions of the roles before it.
+const (
+	RoleViewer int = iota
+	RoleTreasurer
+	RoleOwner
+)
+
+// The largest amount of cheesecoins that can be entered into a command
+const MaxCheesecoinInput = 10000000
+
+// The longest organisation name, which leaves room for the " (Organisation)" suffix within Discord's 100 character choice limit
+const MaxOrgNameLength = 85
+
+// Names which organisations cannot take, as they could be used to impersonate the bot's own accounts
+var reserved_org_names = []string{"treasury", "bank", "casino", "escrow", "stock exchange", "cheese bot", "government", "super user", "admin"}
+
+// Limits on the value of a numeric command option.
+// Cheesecoin amounts are also limited to 2 decimal places.
+type OptionLimit struct {
+	Min        float64
+	Max        float64
+	Cheesecoin bool
 }
 
 const (
@@ -60,6 +237,10 @@ const (
 	AutoCompleteAllAccounts
 	AutoCompleteOwnedOrgs
 	AutoCompleteNone
+	AutoCompleteHeldShares
+	AutoCompleteAllOrgs
+	AutoCompleteEscrows
+	AutoCompleteCampaigns
 )
 
 // Variables used for command line parameters
@@ -72,14 +253,22 @@ type HandlerData struct {
 	channel          *discordgo.Channel
 	interaction      *discordgo.InteractionCreate
 	interaction_data discordgo.ApplicationCommandInteractionData
+	component_data   discordgo.MessageComponentInteractionData
 	user             *discordgo.User
 }
 
 var (
-	data     Data
-	treasury string
-	bank     string
-	casino   string
+	data Data
+	// Held whenever data is read or changed so that each command and background task happens atomically
+	data_mutex sync.Mutex
+	treasury   string
+	bank       string
+	casino     string
+
+	// Direct messages waiting to be sent, so that they are not sent while data_mutex is held
+	message_queue       []QueuedMessage
+	message_queue_mutex sync.Mutex
+	message_queued      = make(chan bool, 1)
 
 	commandHandlers = map[string]func(data_handler HandlerData){
 		"help": func(data_handler HandlerData) {
@@ -99,9 +288,24 @@ var (
 					Value:  "Pays [recipiant] [cheesecoins] from an account (default is personal account)",
 					Inline: false,
 				},
+				{
+					Name:   "/wager",
+					Value:  "Propose a wager of [amount] each with [user] on [terms], optionally settled by an [arbiter] if you disagree, or list your wagers. Both stakes are held in escrow until you agree who won or the arbiter decides, and are refunded if there is no winner after [days].",
+					Inline: false,
+				},
+				{
+					Name:   "/escrow",
+					Value:  "Hold [cheesecoin] for [recipiant] in escrow for [terms], optionally from [from_organisation], until you release it. It is refunded after [days] unless released or disputed, and a super user decides disputes within 7 days or it is refunded. Also release, refund, dispute or list escrow payments.",
+					Inline: false,
+				},
+				{
+					Name:   "/campaign",
+					Value:  "Start a crowdfunding campaign called [title] for [organisation] to raise [goal] within [days], pledge [cheesecoin] to a [campaign], list open campaigns or cancel one. Pledges are held until the end, then paid to the organisation if the goal is reached or refunded if not.",
+					Inline: false,
+				},
 				{
 					Name:   "/transfer_org",
-					Value:  "Transfers [organisation] to [new_owner].",
+					Value:  "Offers [organisation] to [new_owner], who must accept within a day. Transfering the treasury, bank or casino must be confirmed by a super user.",
 					Inline: false,
 				},
 				{
@@ -111,12 +315,77 @@ var (
 				},
 				{
 					Name:   "/delete_org",
-					Value:  "Deletes [organisation] and transfers the remaining funds to your personal account.",
+					Value:  "Deletes [organisation] and transfers the remaining funds to your personal account, after you confirm. Organisations with loans, other owners or other shareholders cannot be deleted.",
+					Inline: false,
+				},
+				{
+					Name:   "/org_add_member",
+					Value:  "Adds [member] to [organisation] as a viewer (can see the balance), treasurer (can also pay) or owner (can also manage the organisation).",
+					Inline: false,
+				},
+				{
+					Name:   "/org_remove_member",
+					Value:  "Removes [member] from [organisation]. You can always remove yourself.",
+					Inline: false,
+				},
+				{
+					Name:   "/org_members",
+					Value:  "Lists the members of [organisation] and their roles.",
+					Inline: false,
+				},
+				{
+					Name:   "/org_set_limit",
+					Value:  "Limits how much [member] can pay from [organisation] each day and in each payment (0 for no limit). Owners are never limited.",
+					Inline: false,
+				},
+				{
+					Name:   "/org_set_approval",
+					Value:  "Payments from [organisation] over [threshold] must be approved by a second treasurer or owner (0 to disable).",
+					Inline: false,
+				},
+				{
+					Name:   "/payroll",
+					Value:  "Manage the employees of [organisation]: `add` or `remove` an [employee] with a [salary] paid every [interval_days], `list` the employees or `run` the payroll now.",
+					Inline: false,
+				},
+				{
+					Name:   "/shares",
+					Value:  "`issue` new shares in [organisation], `transfer` your shares to another user, view your `holdings` or `vote` for the owner of an organisation (votes are weighted by shares and a majority offers them ownership). The treasury, bank and casino cannot issue shares.",
+					Inline: false,
+				},
+				{
+					Name:   "/dividend",
+					Value:  "Pays [cheesecoin] from [organisation] to its shareholders in proportion to their shares.",
+					Inline: false,
+				},
+				{
+					Name:   "/order",
+					Value:  "`buy` or `sell` [shares] in [organisation] at a [price] per share (default is the market price), `cancel` an open order or `list` your orders.",
+					Inline: false,
+				},
+				{
+					Name:   "/market",
+					Value:  "View the best bid and ask for each company, or the order book and recent trades for [organisation].",
+					Inline: false,
+				},
+				{
+					Name:   "/org_profile",
+					Value:  "Sets the [description], [category], [logo_url] and whether the balance is public for [organisation].",
+					Inline: false,
+				},
+				{
+					Name:   "/org_info",
+					Value:  "Shows the profile of [organisation].",
+					Inline: false,
+				},
+				{
+					Name:   "/directory",
+					Value:  "Lists the organisations, optionally only those in [category] or matching [search].",
 					Inline: false,
 				},
 				{
 					Name:   "/create_org",
-					Value:  "Creates a new organisation with [name] and gives you ownership.",
+					Value:  "Creates a new organisation with [name] and gives you ownership. Names must be unique and there may be a creation fee paid to the treasury.",
 					Inline: false,
 				},
 				{
@@ -124,14 +393,39 @@ var (
 					Value:  "Claim your daily 2cc if you are an MP.",
 					Inline: false,
 				},
+				{
+					Name:   "/economy",
+					Value:  "View statistics about the economy and how they have changed over the last 7 and 30 days.",
+					Inline: false,
+				},
+				{
+					Name:   "/chart",
+					Value:  "Draws a chart of the `balance` of one of your accounts or a `economy` statistic over the last [period].",
+					Inline: false,
+				},
 				{
 					Name:   "/sudo_set_wealth_tax",
-					Value:  "Sets the wealth tax rate to [new_tax]%. Can only be done by super user (i.e. head of bank).",
+					Value:  "Sets the wealth tax rate to [new_tax]%, optionally from [effective_date]. Can only be done by super user (i.e. head of bank).",
 					Inline: false,
 				},
 				{
 					Name:   "/sudo_set_transaction_tax",
-					Value:  "Sets the transaction tax rate to [new_tax]%. Can only be done by super user (i.e. head of bank).",
+					Value:  "Sets the transaction tax rate to [new_tax]%, optionally from [effective_date]. Can only be done by super user (i.e. head of bank).",
+					Inline: false,
+				},
+				{
+					Name:   "/sudo_set_winnings_tax",
+					Value:  "Sets the tax on casino winnings to [new_tax]%, optionally from [effective_date]. Winnings do not pay transaction tax. Can only be done by super user (i.e. head of bank).",
+					Inline: false,
+				},
+				{
+					Name:   "/sudo_set_lottery_tax",
+					Value:  "Sets the share of each lottery prize pool paid to the treasury to [new_tax]%, optionally from [effective_date]. Can only be done by super user (i.e. head of bank).",
+					Inline: false,
+				},
+				{
+					Name:   "/sudo_set_sales_tax",
+					Value:  "Sets the sales tax rate charged on payments to organisations to [new_tax]%, optionally from [effective_date]. Can only be done by super user (i.e. head of bank).",
 					Inline: false,
 				},
 				{
@@ -144,6 +438,16 @@ var (
 					Value:  "List the bank holidays coming up soon.",
 					Inline: false,
 				},
+				{
+					Name:   "/sudo_set_org_fee",
+					Value:  "Sets the fee paid to the treasury to create an organisation to [cheesecoin]. Can only be done by super user (i.e. head of bank).",
+					Inline: false,
+				},
+				{
+					Name:   "/sudo_set_ubi",
+					Value:  "Pays [cheesecoin] from the treasury every [interval_days] to everyone active in the last [active_days]. Can only be done by super user (i.e. head of bank).",
+					Inline: false,
+				},
 				{
 					Name:   "/sudo_loan",
 					Value:  "Loans a account an unrestricted amount of cheesecoin. Can only be done by super user (i.e. head of bank).",
@@ -151,7 +455,12 @@ var (
 				},
 				{
 					Name:   "/sudo_set_interest_rate",
-					Value:  "Sets interest rate in percent. Can only be done by super user (i.e. head of bank).",
+					Value:  "Sets interest rate in percent, optionally from [effective_date]. Can only be done by super user (i.e. head of bank).",
+					Inline: false,
+				},
+				{
+					Name:   "/rates_history",
+					Value:  "Lists the recent and scheduled changes to the tax and interest rates.",
 					Inline: false,
 				},
 				{
@@ -163,6 +472,54 @@ var (
 					Name:   "/gamble",
 					Value:  "Gamble [cheesecoins] on [number] being roled (1-6 dice)",
 					Inline: false,
+				}, {
+					Name:   "/blackjack",
+					Value:  "Play blackjack for [stake] against the casino using the Hit, Stand and Double buttons.",
+					Inline: false,
+				}, {
+					Name:   "/blackjack_rules",
+					Value:  "Set the number of [decks], if the dealer hits on soft 17 and what [blackjack_pays]. Only avaliable to the owner of the casino.",
+					Inline: false,
+				}, {
+					Name:   "/roulette",
+					Value:  "Spin the roulette wheel with up to 10 [bets] separated by commas. Bet on a number (pays 35:1), red, black, odd or even (1:1), or dozen1-3 and column1-3 (2:1).",
+					Inline: false,
+				}, {
+					Name:   "/roulette_set_edge",
+					Value:  "Set the percentage [edge] taken off roulette winnings. Only avaliable to the owner of the casino.",
+					Inline: false,
+				}, {
+					Name:   "/lottery",
+					Value:  "Buy [tickets] in the lottery or view its status. The casino owner can start a round with a [ticket_price], the [hours] until the draw, the number of [winners] and the [casino_cut].",
+					Inline: false,
+				}, {
+					Name:   "/gambling_limit",
+					Value:  "View your gambling limits or set your [daily_loss] limit. Raising or removing a limit takes 24 hours.",
+					Inline: false,
+				}, {
+					Name:   "/self_exclude",
+					Value:  "Blocks you from all casino games for [duration]. This cannot be undone.",
+					Inline: false,
+				}, {
+					Name:   "/sudo_gambling_policy",
+					Value:  "Sets the [min_bet_interval] in seconds and whether people with overdue loans are blocked from gambling. Can only be done by super user (i.e. head of bank).",
+					Inline: false,
+				}, {
+					Name:   "/casino_report",
+					Value:  "Shows the bets, payouts, revenue and house edge of the casino over [period], by game. Only avaliable to members of the casino.",
+					Inline: false,
+				}, {
+					Name:   "/casino_digest",
+					Value:  "Get a [daily] casino report instead of a message for each win. Only avaliable to the owner of the casino.",
+					Inline: false,
+				}, {
+					Name:   "/casino_seed",
+					Value:  "Shows the hash of your secret server seed and your client seed. Setting a new [client_seed] reveals the old server seed so you can check your rolls.",
+					Inline: false,
+				}, {
+					Name:   "/casino_verify",
+					Value:  "Checks roll [nonce] made with [server_seed] and [client_seed], with [sides] possible results.",
+					Inline: false,
 				}, {
 					Name:   "/gambling_set_returns",
 					Value:  "Set the returns on the gambling. Only avaliable to the owner of the casino.",
@@ -174,8 +531,8 @@ var (
 			// Get the user data from their discord id
 			user_data := data.Users[data_handler.user.ID]
 
-			description := fmt.Sprintf("**Currency information**\n```\n%-20s %.2f%%\n%-20s %.2f%%\n%-20s %s\n```\n**Your accounts**\n```",
-				"Wealth Tax:", data.WealthTax, "Transaction Tax:", data.TransactionTax, "Total Currency:", format_cheesecoins(total_currency()))
+			description := fmt.Sprintf("**Currency information**\n```\n%-20s %.2f%%\n%-20s %.2f%%\n%-20s %.2f%%\n%-20s %s\n```\n**Your accounts**\n```",
+				"Wealth Tax:", data.WealthTax, "Transaction Tax:", data.TransactionTax, "Sales Tax:", data.SalesTax, "Total Currency:", format_cheesecoins(total_currency()))
 
 			// Add their personal account to the resulting string
 			description += format_account(data.PersonalAccounts[user_data.PersonalAccount])
@@ -191,12 +548,16 @@ var (
 		},
 		"pay": func(data_handler HandlerData) {
 			// Get the recipiant
-			recipiant_account, _ := get_account(data_handler.interaction_data.Options[0].StringValue())
+			recipiant := data_handler.interaction_data.Options[0].StringValue()
+			recipiant_account, ok := get_account(recipiant)
+			if !ok {
+				create_embed("Payment", data_handler.session, data_handler.interaction, "**ERROR:** The recipiant does not exist", []*discordgo.MessageEmbedField{})
+				return
+			}
 			recipiant_name := recipiant_account.Name
 
 			// Get the transaction amount
-			float_amount, _ := data_handler.interaction_data.Options[1].Value.(float64)
-			amount := int(float_amount * 100)
+			amount := cheesecoin_option(data_handler.interaction_data.Options[1])
 
 			// Get the payer - the default being the current user's personal account
 			payer := data.Users[data_handler.user.ID].PersonalAccount
@@ -204,343 +565,2281 @@ var (
 			payer_name := payer_account.Name + " (Personal)"
 			if len(data_handler.interaction_data.Options) > 2 {
 				payer = data_handler.interaction_data.Options[2].StringValue()
-				payer_account, _ = get_account(payer)
+				if !user_has_org(data_handler.user, payer, RoleTreasurer) {
+					create_embed("Payment", data_handler.session, data_handler.interaction, "**ERROR:** You are not a treasurer or owner of that organisation", []*discordgo.MessageEmbedField{})
+					return
+				}
+				payer_account = data.OrganisationAccounts[payer]
 				payer_name = payer_account.Name
-				if !user_has_org(data_handler.user, payer, false) {
-					create_embed("Payment", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** You do not own the ", payer_name, " organisation"), []*discordgo.MessageEmbedField{})
+
+				if err := check_spending_limit(payer_account, data_handler.user.ID, amount); err != "" {
+					create_embed("Payment", data_handler.session, data_handler.interaction, err, []*discordgo.MessageEmbedField{})
+					return
+				}
+
+				// Large payments must be approved by another member
+				if payer_account.ApprovalThreshold > 0 && amount > payer_account.ApprovalThreshold {
+					if payer_account.Balance < amount {
+						create_embed("Payment", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** ", payer_name, " has only ", format_cheesecoins(payer_account.Balance)), []*discordgo.MessageEmbedField{})
+						return
+					}
+
+					payment_id := fmt.Sprint(data.NextPendingPayment)
+					data.NextPendingPayment += 1
+					payment := &PendingPayment{Organisation: payer, Recipiant: recipiant, Amount: amount, Requester: data_handler.user.ID, Expires: time.Now().Add(time.Hour * 24)}
+
+					approvers := 0
+					for member, role := range payer_account.Members {
+						if member != data_handler.user.ID && role >= RoleTreasurer {
+							approvers++
+							send_buttons("Approve payment", data_handler.session, member, fmt.Sprint(format_user(payment.Requester), " would like to pay ", format_cheesecoins(amount), " from ", payer_name, " to ", recipiant_name,
+								". Payments over ", format_cheesecoins(payer_account.ApprovalThreshold), " need a second member to approve them. The request expires <t:", payment.Expires.Unix(), ":R>."),
+								[]discordgo.MessageComponent{
+									discordgo.Button{Label: "Approve", Style: discordgo.SuccessButton, CustomID: "payment_approve:" + payment_id},
+									discordgo.Button{Label: "Reject", Style: discordgo.DangerButton, CustomID: "payment_reject:" + payment_id},
+								})
+						}
+					}
+					if approvers == 0 {
+						create_embed("Payment", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** Payments over ", format_cheesecoins(payer_account.ApprovalThreshold), " from ", payer_name, " need approval but there are no other treasurers or owners to approve it."), []*discordgo.MessageEmbedField{})
+						return
+					}
+					data.PendingPayments[payment_id] = payment
+
+					create_embed("Payment", data_handler.session, data_handler.interaction, fmt.Sprint("Payments over ", format_cheesecoins(payer_account.ApprovalThreshold), " from ", payer_name,
+						" need approval. The other treasurers and owners have been asked to approve the payment of ", format_cheesecoins(amount), " to ", recipiant_name, "."), []*discordgo.MessageEmbedField{})
 					return
 				}
 			}
 
-			sucsess, err, tax := transaction(amount, payer_account, recipiant_account, payer_name, data_handler.session, nil)
+			sucsess, err, tax, sales_tax := sales_transaction(amount, payer_account, recipiant_account, payer_name, data_handler.session, nil)
 			if !sucsess {
 				create_embed("Payment", data_handler.session, data_handler.interaction, err, []*discordgo.MessageEmbedField{})
 				return
 			}
+			record_spending(payer_account, data_handler.user.ID, amount)
 
 			create_embed("Payment", data_handler.session, data_handler.interaction, fmt.Sprint("Sucsessfully transfered ", format_cheesecoins(amount), " from ", payer_name, " to ", recipiant_name,
-				".\n```\nAmount Payed    ", format_cheesecoins(amount), "\nTax           - ", format_cheesecoins(tax), "\nRecieved      = ", format_cheesecoins(amount-tax), "\n```", err),
+				".", format_receipt(amount, tax, sales_tax), err),
 				[]*discordgo.MessageEmbedField{})
 		},
 		"transfer_org": func(data_handler HandlerData) {
 			// Get the organisation
 			organisation := data_handler.interaction_data.Options[0].StringValue()
+			if !user_has_org(data_handler.user, organisation, RoleOwner) {
+				create_embed("Transfer organisation", data_handler.session, data_handler.interaction, "**ERROR:** You do not own that organisation", []*discordgo.MessageEmbedField{})
+				return
+			}
 			organisation_name := data.OrganisationAccounts[organisation].Name
 
-			if !user_has_org(data_handler.user, organisation, true) {
-				create_embed("Transfer organisation", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** You do not own ", organisation_name), []*discordgo.MessageEmbedField{})
+			// Get the recipiant
+			recipiant_id := data_handler.interaction_data.Options[1].StringValue()
+			if _, ok := data.Users[recipiant_id]; !ok || recipiant_id == data_handler.user.ID {
+				create_embed("Transfer organisation", data_handler.session, data_handler.interaction, "**ERROR:** The new owner must be another user of the cheese bot", []*discordgo.MessageEmbedField{})
 				return
 			}
+			recipiant_name := format_user(recipiant_id)
 
-			// Get the recipiant
-			recipiant := data.Users[data_handler.interaction_data.Options[1].StringValue()]
-			recipiant_name := data.PersonalAccounts[recipiant.PersonalAccount].Name
+			for _, offer := range data.TransferOffers {
+				if offer.Organisation == organisation {
+					create_embed("Transfer organisation", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** There is already a pending offer to transfer ", organisation_name), []*discordgo.MessageEmbedField{})
+					return
+				}
+			}
+
+			offer_id := fmt.Sprint(data.NextTransferOffer)
+			data.NextTransferOffer += 1
+			offer := &TransferOffer{Organisation: organisation, From: data_handler.user.ID, To: recipiant_id, Expires: time.Now().Add(time.Hour * 24)}
+			data.TransferOffers[offer_id] = offer
+
+			// The treasury, bank and casino are too important to be given away without a super user agreeing
+			if organisation == treasury || organisation == bank || organisation == casino {
+				for id, usr := range data.Users {
+					if usr.SuperUser {
+						send_buttons("Confirm organisation transfer", data_handler.session, id, fmt.Sprint(format_user(offer.From), " wants to transfer ", organisation_name, " to ", recipiant_name, ". The offer expires <t:", offer.Expires.Unix(), ":R>."),
+							[]discordgo.MessageComponent{
+								discordgo.Button{Label: "Confirm", Style: discordgo.SuccessButton, CustomID: "transfer_org_confirm:" + offer_id},
+								discordgo.Button{Label: "Reject", Style: discordgo.DangerButton, CustomID: "transfer_org_reject:" + offer_id},
+							})
+					}
+				}
 
-			recipiant.Organisations = append(recipiant.Organisations, organisation)
+				create_embed("Transfer organisation", data_handler.session, data_handler.interaction, fmt.Sprint(
+					"A super user must confirm the transfer of ", organisation_name, " before it is offered to ", recipiant_name, "."), []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			offer.Confirmed = true
+			send_transfer_offer(data_handler.session, offer_id)
 
 			create_embed("Transfer organisation", data_handler.session, data_handler.interaction, fmt.Sprint(
-				"Sucessfully transfered ", organisation_name, " to ", recipiant_name), []*discordgo.MessageEmbedField{})
+				"Sucessfully offered ", organisation_name, " to ", recipiant_name, ". They have until <t:", offer.Expires.Unix(), ":f> to accept."), []*discordgo.MessageEmbedField{})
 		},
 		"create_org": func(data_handler HandlerData) {
-			name := data_handler.interaction_data.Options[0].StringValue()
+			name, err := validate_org_name(data_handler.interaction_data.Options[0].StringValue(), "")
+			if err != "" {
+				create_embed("Create organisation", data_handler.session, data_handler.interaction, err, []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			// Pay the creation fee to the treasury
+			fee_result := ""
+			if data.OrgCreationFee > 0 {
+				user_account := data.PersonalAccounts[data.Users[data_handler.user.ID].PersonalAccount]
+				sucsess, err, tax, sales_tax := transaction(data.OrgCreationFee, user_account, data.OrganisationAccounts[treasury], "Organisation creation fee", data_handler.session, nil)
+				if !sucsess {
+					create_embed("Create organisation", data_handler.session, data_handler.interaction, err, []*discordgo.MessageEmbedField{})
+					return
+				}
+				fee_result = fmt.Sprint(". You paid a creation fee of ", format_receipt(data.OrgCreationFee, tax, sales_tax))
+			}
 
-			data.Users[data_handler.user.ID].Organisations = append(data.Users[data_handler.user.ID].Organisations, fmt.Sprint(data.NextOrg))
-			data.OrganisationAccounts[fmt.Sprint(data.NextOrg)] = &Account{Name: name, Balance: 0, Loans: []*Loan{}}
+			data.OrganisationAccounts[fmt.Sprint(data.NextOrg)] = &Account{Name: name, Balance: 0, Loans: []*Loan{}, Members: map[string]int{}, Limits: map[string]*SpendingLimit{}, Payroll: map[string]*Employee{}, Shares: map[string]int{}, Votes: map[string]string{},
+				Category: "Other", Founded: time.Now()}
+			add_org_member(data_handler.user.ID, fmt.Sprint(data.NextOrg), RoleOwner)
 			data.NextOrg += 1
 
 			create_embed("Create organisation", data_handler.session, data_handler.interaction, fmt.Sprint(
-				"Sucessfully created ", name, " which is owned by ", data.PersonalAccounts[data.Users[data_handler.user.ID].PersonalAccount].Name), []*discordgo.MessageEmbedField{})
+				"Sucessfully created ", name, " which is owned by ", data.PersonalAccounts[data.Users[data_handler.user.ID].PersonalAccount].Name, fee_result), []*discordgo.MessageEmbedField{})
 		},
-		"answer_mp_rollcall": func(data_handler HandlerData) {
-			cheese_user := data.Users[data_handler.user.ID]
+		"org_add_member": func(data_handler HandlerData) {
+			// Get the organisation
+			organisation := data_handler.interaction_data.Options[0].StringValue()
+			organisation_account, ok := data.OrganisationAccounts[organisation]
+			if !ok || !user_has_org(data_handler.user, organisation, RoleOwner) {
+				create_embed("Add member", data_handler.session, data_handler.interaction, "**ERROR:** You do not own that organisation", []*discordgo.MessageEmbedField{})
+				return
+			}
 
-			if !cheese_user.Mp {
-				create_embed("Rollcall",
-					data_handler.session, data_handler.interaction, "You are not an MP. Only MPs can claim this benefit.", []*discordgo.MessageEmbedField{})
+			// Get the new member
+			member := data_handler.interaction_data.Options[1].StringValue()
+			if _, ok := data.Users[member]; !ok {
+				create_embed("Add member", data_handler.session, data_handler.interaction, "**ERROR:** The new member must be a user of the cheese bot", []*discordgo.MessageEmbedField{})
 				return
 			}
-			duration := time.Since(cheese_user.LastPay)
-			if duration.Hours() < 15 {
-				create_embed("Rollcall", data_handler.session, data_handler.interaction, fmt.Sprint("You can claim this benefit only once per day. You have last claimed it ", duration.Round(time.Second).String(), " ago"),
-					[]*discordgo.MessageEmbedField{})
+			if member == data_handler.user.ID {
+				create_embed("Add member", data_handler.session, data_handler.interaction, "**ERROR:** You cannot change your own role", []*discordgo.MessageEmbedField{})
 				return
-			} else if duration.Hours() > 33 {
-				cheese_user.PayStreak = 0
 			}
 
-			cheese_user.LastPay = time.Now()
-
-			sucsess, err, _ := transaction(int(math.Min(5., math.Pow(1.1, float64(cheese_user.PayStreak)))*100), data.OrganisationAccounts[treasury], data.PersonalAccounts[cheese_user.PersonalAccount], "Treasury", data_handler.session, data_handler.interaction)
-
-			cheese_user.PayStreak += 1
+			role := int(data_handler.interaction_data.Options[2].IntValue())
+			add_org_member(member, organisation, role)
 
-			if !sucsess {
-				create_embed("Rollcall", data_handler.session, data_handler.interaction, err, []*discordgo.MessageEmbedField{})
-			}
+			send_embed("Organisation membership", data_handler.session, member, fmt.Sprint(format_user(data_handler.user.ID), " has made you a ", strings.ToLower(role_names[role]), " of ", organisation_account.Name, "."), []*discordgo.MessageEmbedField{})
+			create_embed("Add member", data_handler.session, data_handler.interaction, fmt.Sprint(
+				"Sucessfully made ", format_user(member), " a ", strings.ToLower(role_names[role]), " of ", organisation_account.Name), []*discordgo.MessageEmbedField{})
 		},
-		"rename_org": func(data_handler HandlerData) {
+		"org_remove_member": func(data_handler HandlerData) {
 			// Get the organisation
 			organisation := data_handler.interaction_data.Options[0].StringValue()
-			organisation_account := data.OrganisationAccounts[organisation]
-			organisation_name := organisation_account.Name
+			organisation_account, ok := data.OrganisationAccounts[organisation]
+			member := data_handler.interaction_data.Options[1].StringValue()
 
-			if !user_has_org(data_handler.user, organisation, false) {
-				create_embed("Rename organisation", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** You do not own ", organisation_name), []*discordgo.MessageEmbedField{})
+			// Members can always leave, otherwise only owners can remove members
+			if !ok || !(user_has_org(data_handler.user, organisation, RoleOwner) || (member == data_handler.user.ID && user_has_org(data_handler.user, organisation, RoleViewer))) {
+				create_embed("Remove member", data_handler.session, data_handler.interaction, "**ERROR:** You do not own that organisation", []*discordgo.MessageEmbedField{})
 				return
 			}
 
-			new_name := data_handler.interaction_data.Options[1].StringValue()
+			role, ok := organisation_account.Members[member]
+			if !ok {
+				create_embed("Remove member", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** ", format_user(member), " is not a member of ", organisation_account.Name), []*discordgo.MessageEmbedField{})
+				return
+			}
 
-			organisation_account.Name = new_name
+			if role == RoleOwner {
+				owners := 0
+				for _, r := range organisation_account.Members {
+					if r == RoleOwner {
+						owners++
+					}
+				}
+				if owners == 1 {
+					create_embed("Remove member", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** ", organisation_account.Name, " must have at least one owner. Transfer or delete the organisation instead."), []*discordgo.MessageEmbedField{})
+					return
+				}
+			}
 
-			create_embed("Rename organisation", data_handler.session, data_handler.interaction, fmt.Sprint(
-				"Sucessfully renamed ", organisation_name, " to ", new_name), []*discordgo.MessageEmbedField{})
-		},
-		"delete_org": func(data_handler HandlerData) {
-			user_account := data.PersonalAccounts[data.Users[data_handler.user.ID].PersonalAccount]
+			remove_org_member(member, organisation)
 
+			if member != data_handler.user.ID {
+				send_embed("Organisation membership", data_handler.session, member, fmt.Sprint(format_user(data_handler.user.ID), " has removed you from ", organisation_account.Name, "."), []*discordgo.MessageEmbedField{})
+			}
+			create_embed("Remove member", data_handler.session, data_handler.interaction, fmt.Sprint(
+				"Sucessfully removed ", format_user(member), " from ", organisation_account.Name), []*discordgo.MessageEmbedField{})
+		},
+		"org_members": func(data_handler HandlerData) {
 			// Get the organisation
 			organisation := data_handler.interaction_data.Options[0].StringValue()
-			organisation_name := data.OrganisationAccounts[organisation].Name
-
-			if !user_has_org(data_handler.user, organisation, true) {
-				create_embed("Delete organisation", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** You do not own ", organisation_name), []*discordgo.MessageEmbedField{})
+			organisation_account, ok := data.OrganisationAccounts[organisation]
+			if !ok || !user_has_org(data_handler.user, organisation, RoleViewer) {
+				create_embed("Organisation members", data_handler.session, data_handler.interaction, "**ERROR:** You are not a member of that organisation", []*discordgo.MessageEmbedField{})
 				return
 			}
 
-			if organisation == treasury {
-				create_embed("Delete organisation", data_handler.session, data_handler.interaction, "**ERROR:** You cannot delete the treasury!", []*discordgo.MessageEmbedField{})
-				return
+			result := fmt.Sprint("**", organisation_account.Name, "**\n```")
+			for role := RoleOwner; role >= RoleViewer; role-- {
+				for member, member_role := range organisation_account.Members {
+					if member_role == role {
+						result += fmt.Sprintf("\n%-20s %s", format_user(member)+":", role_names[role])
+					}
+				}
 			}
+			result += "\n```"
 
-			if organisation == bank {
-				create_embed("Delete organisation", data_handler.session, data_handler.interaction, "**ERROR:** You cannot delete the bank!", []*discordgo.MessageEmbedField{})
+			create_embed("Organisation members", data_handler.session, data_handler.interaction, result, []*discordgo.MessageEmbedField{})
+		},
+		"org_set_limit": func(data_handler HandlerData) {
+			// Get the organisation
+			organisation := data_handler.interaction_data.Options[0].StringValue()
+			organisation_account, ok := data.OrganisationAccounts[organisation]
+			if !ok || !user_has_org(data_handler.user, organisation, RoleOwner) {
+				create_embed("Set spending limit", data_handler.session, data_handler.interaction, "**ERROR:** You do not own that organisation", []*discordgo.MessageEmbedField{})
 				return
 			}
 
-			if organisation == casino {
-				create_embed("Delete organisation", data_handler.session, data_handler.interaction, "**ERROR:** You cannot delete the casino! It is to important.", []*discordgo.MessageEmbedField{})
+			member := data_handler.interaction_data.Options[1].StringValue()
+			if _, ok := organisation_account.Members[member]; !ok {
+				create_embed("Set spending limit", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** ", format_user(member), " is not a member of ", organisation_account.Name), []*discordgo.MessageEmbedField{})
 				return
 			}
 
-			org_account := data.OrganisationAccounts[organisation]
-			sucsess, err, tax := transaction(org_account.Balance, org_account, user_account, "destroyed organisation", data_handler.session, nil)
-			if !sucsess {
-				create_embed("Delete organisation", data_handler.session, data_handler.interaction, err, []*discordgo.MessageEmbedField{})
-				return
+			limit, ok := organisation_account.Limits[member]
+			if !ok {
+				limit = &SpendingLimit{}
+				organisation_account.Limits[member] = limit
 			}
+			limit.Daily = cheesecoin_option(data_handler.interaction_data.Options[2])
+			limit.PerTransaction = cheesecoin_option(data_handler.interaction_data.Options[3])
 
-			delete(data.OrganisationAccounts, organisation)
-
-			create_embed("Delete organisation", data_handler.session, data_handler.interaction, fmt.Sprint(
-				"Sucessfully deleted ", organisation_name, " all funds have been transfered to your personal account (with ", tax, " in tax)"), []*discordgo.MessageEmbedField{})
+			create_embed("Set spending limit", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully limited ", format_user(member), " to ", format_limit(limit.Daily), " per day and ",
+				format_limit(limit.PerTransaction), " per payment from ", organisation_account.Name, "."), []*discordgo.MessageEmbedField{})
 		},
-		"sudo_set_wealth_tax": func(data_handler HandlerData) {
-			if !data.Users[data_handler.user.ID].SuperUser {
-				create_embed("Set Wealth Tax", data_handler.session, data_handler.interaction, "**ERROR:** You are not a super user", []*discordgo.MessageEmbedField{})
+		"org_set_approval": func(data_handler HandlerData) {
+			// Get the organisation
+			organisation := data_handler.interaction_data.Options[0].StringValue()
+			organisation_account, ok := data.OrganisationAccounts[organisation]
+			if !ok || !user_has_org(data_handler.user, organisation, RoleOwner) {
+				create_embed("Set approval threshold", data_handler.session, data_handler.interaction, "**ERROR:** You do not own that organisation", []*discordgo.MessageEmbedField{})
 				return
 			}
 
-			data.WealthTax = data_handler.interaction_data.Options[0].Value.(float64)
+			organisation_account.ApprovalThreshold = cheesecoin_option(data_handler.interaction_data.Options[1])
 
-			create_embed("Set Wealth Tax", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully set wealth tax to ", data.WealthTax, "%."), []*discordgo.MessageEmbedField{})
-		},
-		"sudo_set_transaction_tax": func(data_handler HandlerData) {
-			if !data.Users[data_handler.user.ID].SuperUser {
-				create_embed("Set Transaction Tax", data_handler.session, data_handler.interaction, "**ERROR:** You are not a super user", []*discordgo.MessageEmbedField{})
+			if organisation_account.ApprovalThreshold == 0 {
+				create_embed("Set approval threshold", data_handler.session, data_handler.interaction, fmt.Sprint("Payments from ", organisation_account.Name, " no longer need approval."), []*discordgo.MessageEmbedField{})
 				return
 			}
-
-			data.TransactionTax = data_handler.interaction_data.Options[0].Value.(float64)
-
-			create_embed("Set Transaction Tax", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully set transaction tax to ", data.TransactionTax, "%."), []*discordgo.MessageEmbedField{})
+			create_embed("Set approval threshold", data_handler.session, data_handler.interaction, fmt.Sprint("Payments over ", format_cheesecoins(organisation_account.ApprovalThreshold), " from ", organisation_account.Name,
+				" now need to be approved by a second treasurer or owner."), []*discordgo.MessageEmbedField{})
 		},
-		"sudo_set_bank_holiday": func(data_handler HandlerData) {
-			if !data.Users[data_handler.user.ID].BankHolidaySetter {
-				create_embed("Set Bank Holiday", data_handler.session, data_handler.interaction, "**ERROR:** You are not a user eligible to set bank holidays", []*discordgo.MessageEmbedField{})
+		"payroll": func(data_handler HandlerData) {
+			subcommand := data_handler.interaction_data.Options[0]
+
+			// Get the organisation
+			organisation := subcommand.Options[0].StringValue()
+			organisation_account, ok := data.OrganisationAccounts[organisation]
+			role := RoleOwner
+			if subcommand.Name == "list" {
+				role = RoleViewer
+			} else if subcommand.Name == "run" {
+				role = RoleTreasurer
+			}
+			if !ok || !user_has_org(data_handler.user, organisation, role) {
+				create_embed("Payroll", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** You must be a ", strings.ToLower(role_names[role]), " of that organisation"), []*discordgo.MessageEmbedField{})
 				return
 			}
-			day := data_handler.interaction_data.Options[0].IntValue()
-			month := data_handler.interaction_data.Options[1].IntValue()
-			enabled := data_handler.interaction_data.Options[2].BoolValue()
-			holiday := month<<12 + day
-			contains_time := contains_int64(data.BankHolidays, holiday)
 
-			result := ""
-			if enabled {
-				if contains_time {
-					result = "is already a bank holiday"
-
-				} else {
-					result = "is now a bank holiday"
-					data.BankHolidays = append(data.BankHolidays, holiday)
+			switch subcommand.Name {
+			case "add":
+				employee := subcommand.Options[1].StringValue()
+				if _, ok := data.Users[employee]; !ok {
+					create_embed("Payroll", data_handler.session, data_handler.interaction, "**ERROR:** The employee must be a user of the cheese bot", []*discordgo.MessageEmbedField{})
+					return
 				}
-			} else {
-				if contains_time {
-					result = "is no longer a bank holiday"
-					data.BankHolidays = remove_int64(data.BankHolidays, holiday)
-				} else {
-					result = "was already not a bank holiday"
+				salary := cheesecoin_option(subcommand.Options[2])
+				interval := int(subcommand.Options[3].IntValue())
+
+				organisation_account.Payroll[employee] = &Employee{Salary: salary, Interval: interval, LastPaid: time.Now()}
+
+				send_embed("Payroll", data_handler.session, employee, fmt.Sprint(organisation_account.Name, " will pay you ", format_cheesecoins(salary), " every ", interval, " day(s)."), []*discordgo.MessageEmbedField{})
+				create_embed("Payroll", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully added ", format_user(employee), " to the payroll of ", organisation_account.Name, " with a salary of ", format_cheesecoins(salary),
+					" every ", interval, " day(s). They will first be paid <t:", time.Now().AddDate(0, 0, interval).Unix(), ":R>."), []*discordgo.MessageEmbedField{})
+			case "remove":
+				employee := subcommand.Options[1].StringValue()
+				if _, ok := organisation_account.Payroll[employee]; !ok {
+					create_embed("Payroll", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** ", format_user(employee), " is not on the payroll of ", organisation_account.Name), []*discordgo.MessageEmbedField{})
+					return
 				}
+				delete(organisation_account.Payroll, employee)
+
+				send_embed("Payroll", data_handler.session, employee, fmt.Sprint(organisation_account.Name, " has removed you from its payroll."), []*discordgo.MessageEmbedField{})
+				create_embed("Payroll", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully removed ", format_user(employee), " from the payroll of ", organisation_account.Name), []*discordgo.MessageEmbedField{})
+			case "list":
+				result := fmt.Sprint("**", organisation_account.Name, "**\n```")
+				total := 0
+				for id, employee := range organisation_account.Payroll {
+					result += fmt.Sprintf("\n%-20s %s every %d day(s)", format_user(id)+":", format_cheesecoins(employee.Salary), employee.Interval)
+					total += employee.Salary
+				}
+				if len(organisation_account.Payroll) == 0 {
+					result += "\nNo employees."
+				}
+				result += fmt.Sprintf("\n\n%-20s %s\n```", "Total per run:", format_cheesecoins(total))
 
-			}
+				create_embed("Payroll", data_handler.session, data_handler.interaction, result, []*discordgo.MessageEmbedField{})
+			case "run":
+				employees := []string{}
+				for id := range organisation_account.Payroll {
+					employees = append(employees, id)
+				}
+				if len(employees) == 0 {
+					create_embed("Payroll", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** ", organisation_account.Name, " has no employees"), []*discordgo.MessageEmbedField{})
+					return
+				}
 
-			create_embed("Set Bank Holiday", data_handler.session, data_handler.interaction, fmt.Sprint(format_date(holiday), " ", result, "."), []*discordgo.MessageEmbedField{})
-		},
-		"bank_holidays": func(data_handler HandlerData) {
+				// Running the payroll by hand is a payment by the member, so it is subject to their limits and the approval threshold
+				total := 0
+				for _, id := range employees {
+					total += organisation_account.Payroll[id].Salary
+				}
+				if err := check_spending_limit(organisation_account, data_handler.user.ID, total); err != "" {
+					create_embed("Payroll", data_handler.session, data_handler.interaction, err, []*discordgo.MessageEmbedField{})
+					return
+				}
+				if err := check_approval_threshold(organisation_account, total); err != "" {
+					create_embed("Payroll", data_handler.session, data_handler.interaction, err, []*discordgo.MessageEmbedField{})
+					return
+				}
 
-			result := ""
-			if len(data.BankHolidays) > 0 {
-				for _, t := range data.BankHolidays {
-					result += fmt.Sprint("\nBank holiday on ", format_date(t), " ", result)
+				paid, summary := run_payroll(data_handler.session, organisation, employees)
+				if paid {
+					record_spending(organisation_account, data_handler.user.ID, total)
 				}
-				fmt.Print("result: ", result)
-			} else {
-				result += "No Bank holidays."
+				create_embed("Payroll", data_handler.session, data_handler.interaction, summary, []*discordgo.MessageEmbedField{})
 			}
+		},
+		"shares": func(data_handler HandlerData) {
+			subcommand := data_handler.interaction_data.Options[0]
+
+			switch subcommand.Name {
+			case "issue":
+				organisation := subcommand.Options[0].StringValue()
+				organisation_account, ok := data.OrganisationAccounts[organisation]
+				if !ok || !user_has_org(data_handler.user, organisation, RoleOwner) {
+					create_embed("Shares", data_handler.session, data_handler.interaction, "**ERROR:** You do not own that organisation", []*discordgo.MessageEmbedField{})
+					return
+				}
+				if organisation == treasury || organisation == bank || organisation == casino {
+					create_embed("Shares", data_handler.session, data_handler.interaction, "**ERROR:** The treasury, bank and casino cannot issue shares", []*discordgo.MessageEmbedField{})
+					return
+				}
+				amount := int(subcommand.Options[1].IntValue())
+
+				// Get the holder - the default being the current user
+				holder := data_handler.user.ID
+				if len(subcommand.Options) > 2 {
+					holder = subcommand.Options[2].StringValue()
+					if _, ok := data.Users[holder]; !ok {
+						create_embed("Shares", data_handler.session, data_handler.interaction, "**ERROR:** The shareholder must be a user of the cheese bot", []*discordgo.MessageEmbedField{})
+						return
+					}
+				}
 
-			create_embed("Bank Holidays", data_handler.session, data_handler.interaction, result, []*discordgo.MessageEmbedField{})
+				organisation_account.Shares[holder] += amount
+
+				if holder != data_handler.user.ID {
+					send_embed("Shares", data_handler.session, holder, fmt.Sprint(organisation_account.Name, " has issued you ", amount, " shares."), []*discordgo.MessageEmbedField{})
+				}
+				create_embed("Shares", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully issued ", amount, " shares in ", organisation_account.Name, " to ", format_user(holder),
+					". There are now ", total_shares(organisation_account), " shares."), []*discordgo.MessageEmbedField{})
+			case "transfer":
+				organisation := subcommand.Options[0].StringValue()
+				organisation_account, ok := data.OrganisationAccounts[organisation]
+				if !ok {
+					create_embed("Shares", data_handler.session, data_handler.interaction, "**ERROR:** That organisation does not exist", []*discordgo.MessageEmbedField{})
+					return
+				}
+				recipiant := subcommand.Options[1].StringValue()
+				if _, ok := data.Users[recipiant]; !ok || recipiant == data_handler.user.ID {
+					create_embed("Shares", data_handler.session, data_handler.interaction, "**ERROR:** The recipiant must be another user of the cheese bot", []*discordgo.MessageEmbedField{})
+					return
+				}
+				amount := int(subcommand.Options[2].IntValue())
+
+				if organisation_account.Shares[data_handler.user.ID] < amount {
+					create_embed("Shares", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** You have only ", organisation_account.Shares[data_handler.user.ID], " shares in ", organisation_account.Name), []*discordgo.MessageEmbedField{})
+					return
+				}
+				transfer_shares(organisation_account, data_handler.user.ID, recipiant, amount)
+
+				send_embed("Shares", data_handler.session, recipiant, fmt.Sprint(format_user(data_handler.user.ID), " has given you ", amount, " shares in ", organisation_account.Name, "."), []*discordgo.MessageEmbedField{})
+				create_embed("Shares", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully transfered ", amount, " shares in ", organisation_account.Name, " to ", format_user(recipiant), "."), []*discordgo.MessageEmbedField{})
+			case "holdings":
+				result := "**Your shares**\n```"
+				any_shares := false
+				for _, account := range data.OrganisationAccounts {
+					if held := held_shares(account, data_handler.user.ID); held > 0 {
+						any_shares = true
+						result += fmt.Sprintf("\n%-20s %d / %d (%.2f%%)", account.Name+":", held, total_shares(account), 100*float64(held)/float64(total_shares(account)))
+					}
+				}
+				if !any_shares {
+					result += "\nNo shares."
+				}
+				result += "\n```"
+
+				create_embed("Shares", data_handler.session, data_handler.interaction, result, []*discordgo.MessageEmbedField{})
+			case "vote":
+				organisation := subcommand.Options[0].StringValue()
+				organisation_account, ok := data.OrganisationAccounts[organisation]
+				if !ok || held_shares(organisation_account, data_handler.user.ID) == 0 {
+					create_embed("Shares", data_handler.session, data_handler.interaction, "**ERROR:** You do not have any shares in that organisation", []*discordgo.MessageEmbedField{})
+					return
+				}
+				if organisation == treasury || organisation == bank || organisation == casino {
+					create_embed("Shares", data_handler.session, data_handler.interaction, "**ERROR:** The owners of the treasury, bank and casino cannot be chosen by shareholders", []*discordgo.MessageEmbedField{})
+					return
+				}
+				candidate := subcommand.Options[1].StringValue()
+				if _, ok := data.Users[candidate]; !ok {
+					create_embed("Shares", data_handler.session, data_handler.interaction, "**ERROR:** The candidate must be a user of the cheese bot", []*discordgo.MessageEmbedField{})
+					return
+				}
+
+				organisation_account.Votes[data_handler.user.ID] = candidate
+
+				// Count the votes, weighted by the number of shares each voter has now, including any they have put up for sale
+				support := 0
+				holdings := shareholdings(organisation_account)
+				for voter, voted_for := range organisation_account.Votes {
+					if voted_for == candidate {
+						support += holdings[voter]
+					}
+				}
+
+				result := fmt.Sprint("You have voted for ", format_user(candidate), " to own ", organisation_account.Name, ". They have the support of ", support, " out of ", total_shares(organisation_account), " shares.")
+				if support*2 > total_shares(organisation_account) {
+					// The vote replaces any other offer to transfer the organisation, and the candidate must accept it like any other transfer
+					for id, offer := range data.TransferOffers {
+						if offer.Organisation == organisation {
+							delete(data.TransferOffers, id)
+							if !offer.ShareholderVote {
+								send_embed("Transfer organisation", data_handler.session, offer.From, fmt.Sprint("Your offer to transfer ", organisation_account.Name, " to ", format_user(offer.To), " has been cancelled by a vote of its shareholders."), []*discordgo.MessageEmbedField{})
+							}
+						}
+					}
+					organisation_account.Votes = map[string]string{}
+
+					offer_id := fmt.Sprint(data.NextTransferOffer)
+					data.NextTransferOffer += 1
+					data.TransferOffers[offer_id] = &TransferOffer{Organisation: organisation, To: candidate, Expires: time.Now().Add(time.Hour * 24), Confirmed: true, ShareholderVote: true}
+					send_transfer_offer(data_handler.session, offer_id)
+
+					for _, owner := range org_owners(organisation_account) {
+						if owner != candidate {
+							send_embed("Shareholder vote", data_handler.session, owner, fmt.Sprint("The shareholders of ", organisation_account.Name, " have voted for ", format_user(candidate), " to own it. If they accept, you will become a viewer."), []*discordgo.MessageEmbedField{})
+						}
+					}
+					result += fmt.Sprint(" This is a majority so ", format_user(candidate), " has been offered ownership.")
+				}
+
+				create_embed("Shares", data_handler.session, data_handler.interaction, result, []*discordgo.MessageEmbedField{})
+			}
 		},
-		"sudo_loan": func(data_handler HandlerData) {
-			if !user_has_org(data_handler.user, bank, false) {
-				create_embed("Loan", data_handler.session, data_handler.interaction, "**ERROR:** You are not a super user", []*discordgo.MessageEmbedField{})
+		"dividend": func(data_handler HandlerData) {
+			organisation := data_handler.interaction_data.Options[0].StringValue()
+			organisation_account, ok := data.OrganisationAccounts[organisation]
+			if !ok || !user_has_org(data_handler.user, organisation, RoleOwner) {
+				create_embed("Dividend", data_handler.session, data_handler.interaction, "**ERROR:** You do not own that organisation", []*discordgo.MessageEmbedField{})
 				return
 			}
 
-			recipiant := data_handler.interaction_data.Options[0].StringValue()
-			recipiant_account, _ := get_account(recipiant)
+			amount := cheesecoin_option(data_handler.interaction_data.Options[1])
+			total := total_shares(organisation_account)
+			if total == 0 {
+				create_embed("Dividend", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** ", organisation_account.Name, " has not issued any shares"), []*discordgo.MessageEmbedField{})
+				return
+			}
+			if organisation_account.Balance < amount {
+				create_embed("Dividend", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** ", organisation_account.Name, " has only ", format_cheesecoins(organisation_account.Balance)), []*discordgo.MessageEmbedField{})
+				return
+			}
+			if err := check_approval_threshold(organisation_account, amount); err != "" {
+				create_embed("Dividend", data_handler.session, data_handler.interaction, err, []*discordgo.MessageEmbedField{})
+				return
+			}
 
-			// Get the transaction amount
-			float_amount, _ := data_handler.interaction_data.Options[1].Value.(float64)
-			amount := int(float_amount * 100)
+			// Each holder is paid in proportion to their shares, rounding down so that no more than the amount is paid
+			result := fmt.Sprint("Sucessfully paid a dividend of ", format_cheesecoins(amount), " from ", organisation_account.Name, ".\n```")
+			paid, failed := 0, ""
+			for holder, held := range shareholdings(organisation_account) {
+				payment := amount * held / total
+				if payment == 0 {
+					continue
+				}
+				if sucsess, err, _, _ := transaction(payment, organisation_account, data.PersonalAccounts[data.Users[holder].PersonalAccount], organisation_account.Name+" (Dividend)", data_handler.session, nil); !sucsess {
+					failed += fmt.Sprint("\n", format_user(holder), ": ", err)
+					continue
+				}
+				paid += payment
+				result += fmt.Sprintf("\n%-20s %s", format_user(holder)+":", format_cheesecoins(payment))
+			}
+			result += fmt.Sprintf("\n\n%-20s %s\n```", "Total:", format_cheesecoins(paid))
+			if failed != "" {
+				result += "\n**Not paid:**" + failed
+			}
 
-			sucsess, result, _ := transaction(amount, data.OrganisationAccounts[bank], recipiant_account, "The Bank", data_handler.session, nil)
+			create_embed("Dividend", data_handler.session, data_handler.interaction, result, []*discordgo.MessageEmbedField{})
+		},
+		"order": func(data_handler HandlerData) {
+			subcommand := data_handler.interaction_data.Options[0]
+
+			switch subcommand.Name {
+			case "buy", "sell":
+				organisation := subcommand.Options[0].StringValue()
+				organisation_account, ok := data.OrganisationAccounts[organisation]
+				if !ok || total_shares(organisation_account) == 0 {
+					create_embed("Order", data_handler.session, data_handler.interaction, "**ERROR:** That organisation has not issued any shares", []*discordgo.MessageEmbedField{})
+					return
+				}
 
-			if sucsess {
-				result = fmt.Sprint("A ", format_cheesecoins(amount), " loan has been granted to ", recipiant_account.Name, " with an interest rate of ", fmt.Sprintf("%.2f%%", data.LoanInterest), ".")
-				recipiant_account.Loans = append(recipiant_account.Loans, &Loan{Start: time.Now(), AmountDue: int(math.Ceil(float64(amount) * (data.LoanInterest + 100) / 100)), LoanValue: amount})
+				order := &Order{Organisation: organisation, User: data_handler.user.ID, Buy: subcommand.Name == "buy", Shares: int(subcommand.Options[1].IntValue())}
+				if len(subcommand.Options) > 2 {
+					order.Price = cheesecoin_option(subcommand.Options[2])
+				}
+
+				err, result := place_order(data_handler.session, order)
+				if err != "" {
+					create_embed("Order", data_handler.session, data_handler.interaction, err, []*discordgo.MessageEmbedField{})
+					return
+				}
+
+				price := "market price"
+				if order.Price > 0 {
+					price = format_cheesecoins(order.Price) + " per share"
+				}
+				create_embed("Order", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully placed an order to ", subcommand.Name, " ", subcommand.Options[1].IntValue(), " shares in ", organisation_account.Name, " at ", price, ".\n", result), []*discordgo.MessageEmbedField{})
+			case "cancel":
+				id := fmt.Sprint(subcommand.Options[0].IntValue())
+				for _, order := range data.Orders {
+					if order.Id == id && order.User == data_handler.user.ID {
+						cancel_order(order)
+						create_embed("Order", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully cancelled order #", id, ". Anything held in escrow has been returned."), []*discordgo.MessageEmbedField{})
+						return
+					}
+				}
+				create_embed("Order", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** You do not have an open order #", id), []*discordgo.MessageEmbedField{})
+			case "list":
+				result := "**Your open orders**\n```"
+				any_orders := false
+				for _, order := range data.Orders {
+					if order.User == data_handler.user.ID {
+						any_orders = true
+						side := "Sell"
+						if order.Buy {
+							side = "Buy"
+						}
+						result += fmt.Sprintf("\n#%-5s %-4s %6d x %-12s %s", order.Id, side, order.Shares, format_cheesecoins(order.Price), data.OrganisationAccounts[order.Organisation].Name)
+					}
+				}
+				if !any_orders {
+					result += "\nNo open orders."
+				}
+				result += "\n```"
+
+				create_embed("Order", data_handler.session, data_handler.interaction, result, []*discordgo.MessageEmbedField{})
 			}
+		},
+		"market": func(data_handler HandlerData) {
+			result := fmt.Sprintf("```\n%-20s %12s %12s %12s", "", "Bid", "Ask", "Last")
 
-			create_embed("Loan", data_handler.session, data_handler.interaction, result, []*discordgo.MessageEmbedField{})
+			if len(data_handler.interaction_data.Options) == 0 {
+				for id, account := range data.OrganisationAccounts {
+					if total_shares(account) > 0 {
+						result += format_market(id)
+					}
+				}
+				result += "\n```"
+
+				create_embed("Market", data_handler.session, data_handler.interaction, result, []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			organisation := data_handler.interaction_data.Options[0].StringValue()
+			if _, ok := data.OrganisationAccounts[organisation]; !ok {
+				create_embed("Market", data_handler.session, data_handler.interaction, "**ERROR:** That organisation does not exist", []*discordgo.MessageEmbedField{})
+				return
+			}
+			result += format_market(organisation) + "\n```\n**Order book**\n```"
+			for _, buy := range []bool{false, true} {
+				book := order_book(organisation, buy)
+				if len(book) > 5 {
+					book = book[:5]
+				}
+				for _, order := range book {
+					side := "Ask"
+					if buy {
+						side = "Bid"
+					}
+					result += fmt.Sprintf("\n%-4s %6d x %s", side, order.Shares, format_cheesecoins(order.Price))
+				}
+			}
+			result += "\n```\n**Recent trades**"
+			trades := 0
+			for i := len(data.Trades) - 1; i >= 0 && trades < 10; i-- {
+				trade := data.Trades[i]
+				if trade.Organisation == organisation {
+					trades++
+					result += fmt.Sprint("\n<t:", trade.Time.Unix(), ":f> ", trade.Shares, " shares at ", format_cheesecoins(trade.Price), " from ", format_user(trade.Seller), " to ", format_user(trade.Buyer))
+				}
+			}
+			if trades == 0 {
+				result += "\nNo trades."
+			}
+
+			create_embed("Market", data_handler.session, data_handler.interaction, result, []*discordgo.MessageEmbedField{})
 		},
-		"sudo_set_interest_rate": func(data_handler HandlerData) {
-			if !user_has_org(data_handler.user, bank, false) {
-				create_embed("Set Interest Rate", data_handler.session, data_handler.interaction, "**ERROR:** You are not a super user", []*discordgo.MessageEmbedField{})
+		"org_profile": func(data_handler HandlerData) {
+			organisation := data_handler.interaction_data.Options[0].StringValue()
+			organisation_account, ok := data.OrganisationAccounts[organisation]
+			if !ok || !user_has_org(data_handler.user, organisation, RoleOwner) {
+				create_embed("Organisation profile", data_handler.session, data_handler.interaction, "**ERROR:** You do not own that organisation", []*discordgo.MessageEmbedField{})
 				return
 			}
 
-			data.LoanInterest = data_handler.interaction_data.Options[0].Value.(float64)
+			for _, option := range data_handler.interaction_data.Options[1:] {
+				switch option.Name {
+				case "description":
+					if len(option.StringValue()) > 1000 {
+						create_embed("Organisation profile", data_handler.session, data_handler.interaction, "**ERROR:** The description cannot be longer than 1000 characters", []*discordgo.MessageEmbedField{})
+						return
+					}
+					organisation_account.Description = option.StringValue()
+				case "category":
+					organisation_account.Category = option.StringValue()
+				case "logo_url":
+					if !strings.HasPrefix(option.StringValue(), "https://") {
+						create_embed("Organisation profile", data_handler.session, data_handler.interaction, "**ERROR:** The logo url must start with https://", []*discordgo.MessageEmbedField{})
+						return
+					}
+					organisation_account.LogoUrl = option.StringValue()
+				case "public_balance":
+					organisation_account.PublicBalance = option.BoolValue()
+				}
+			}
 
-			create_embed("Set Interest Rate", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully set interest rate to ", data.LoanInterest, "%."), []*discordgo.MessageEmbedField{})
+			create_embed("Organisation profile", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully updated the profile of ", organisation_account.Name, ". View it with /org_info."), []*discordgo.MessageEmbedField{})
 		},
-		"view_bank_loans": func(data_handler HandlerData) {
-			cheese_user := data.Users[data_handler.user.ID]
+		"org_info": func(data_handler HandlerData) {
+			organisation := data_handler.interaction_data.Options[0].StringValue()
+			organisation_account, ok := data.OrganisationAccounts[organisation]
+			if !ok {
+				create_embed("Organisation info", data_handler.session, data_handler.interaction, "**ERROR:** That organisation does not exist", []*discordgo.MessageEmbedField{})
+				return
+			}
 
-			result := "**Your loans:**"
-			r, any_loans := format_loans(data.PersonalAccounts[cheese_user.PersonalAccount])
-			result += r
-			for _, org := range cheese_user.Organisations {
-				r, loan := format_loans(data.OrganisationAccounts[org])
-				result += r
-				if loan {
-					any_loans = true
+			description := organisation_account.Description
+			if description == "" {
+				description = "No description."
+			}
+
+			founded := "Unknown"
+			if !organisation_account.Founded.IsZero() {
+				founded = fmt.Sprint("<t:", organisation_account.Founded.Unix(), ":D>")
+			}
+
+			owners := []string{}
+			for _, owner := range org_owners(organisation_account) {
+				owners = append(owners, format_user(owner))
+			}
+			sort.Strings(owners)
+
+			fields := []*discordgo.MessageEmbedField{
+				{Name: "Category", Value: organisation_category(organisation_account), Inline: true},
+				{Name: "Founded", Value: founded, Inline: true},
+				{Name: "Owners", Value: strings.Join(owners, ", ") + ".", Inline: false},
+			}
+			if organisation_account.PublicBalance || user_has_org(data_handler.user, organisation, RoleViewer) {
+				fields = append(fields, &discordgo.MessageEmbedField{Name: "Balance", Value: format_cheesecoins(organisation_account.Balance), Inline: true})
+			}
+			if total_shares(organisation_account) > 0 {
+				fields = append(fields, &discordgo.MessageEmbedField{Name: "Shares", Value: fmt.Sprint(total_shares(organisation_account)), Inline: true})
+			}
+			if organisation_account.LogoUrl != "" {
+				fields = append(fields, &discordgo.MessageEmbedField{Name: "Logo", Value: organisation_account.LogoUrl, Inline: false})
+			}
+
+			create_embed(organisation_account.Name, data_handler.session, data_handler.interaction, description, fields)
+		},
+		"directory": func(data_handler HandlerData) {
+			category, search := "", ""
+			for _, option := range data_handler.interaction_data.Options {
+				switch option.Name {
+				case "category":
+					category = option.StringValue()
+				case "search":
+					search = option.StringValue()
 				}
 			}
-			if !any_loans {
-				result += "\nNo loans."
+
+			organisations := option_choice{}
+			for id, account := range data.OrganisationAccounts {
+				if category == "" || organisation_category(account) == category {
+					organisations = append(organisations, &discordgo.ApplicationCommandOptionChoice{Name: account.Name, Value: id})
+				}
+			}
+			sort.Slice(organisations, func(i, j int) bool { return organisations[i].Name < organisations[j].Name })
+
+			// Order by how well the name matches the search
+			if search != "" {
+				matches := fuzzy.FindFrom(search, organisations)
+				results := make(option_choice, len(matches))
+				for i, match := range matches {
+					results[i] = organisations[match.Index]
+				}
+				organisations = results
 			}
 
-			if user_has_org(data_handler.user, bank, false) {
-				result += "\n\n**All loans:**"
-				for _, acc := range data.PersonalAccounts {
-					r, loan := format_loans(acc)
-					result += r
-					if loan {
-						any_loans = true
-					}
+			result := ""
+			for _, c := range org_categories {
+				if category != "" && c != category {
+					continue
 				}
-				for _, acc := range data.OrganisationAccounts {
-					r, loan := format_loans(acc)
-					result += r
-					if loan {
-						any_loans = true
+				section := ""
+				for _, choice := range organisations {
+					account := data.OrganisationAccounts[choice.Value.(string)]
+					if organisation_category(account) == c {
+						section += "\n• " + account.Name
+						if account.Description != "" {
+							section += " - " + truncate(account.Description, 60)
+						}
 					}
 				}
-				if !any_loans {
-					result += "\nNo loans."
+				if section != "" {
+					result += "\n**" + c + "**" + section + "\n"
 				}
 			}
+			if result == "" {
+				result = "No organisations found."
+			}
 
-			create_embed("View Loans", data_handler.session, data_handler.interaction, result, []*discordgo.MessageEmbedField{})
+			create_embed("Directory", data_handler.session, data_handler.interaction, truncate(result, 4000), []*discordgo.MessageEmbedField{})
 		},
-		"gamble": func(data_handler HandlerData) {
-			// Get the transaction amount
-			float_amount, _ := data_handler.interaction_data.Options[0].Value.(float64)
-			amount := int(float_amount * 100)
+		"answer_mp_rollcall": func(data_handler HandlerData) {
+			cheese_user := data.Users[data_handler.user.ID]
 
-			cheese_account := data.PersonalAccounts[data.Users[data_handler.user.ID].PersonalAccount]
+			if !cheese_user.Mp {
+				create_embed("Rollcall",
+					data_handler.session, data_handler.interaction, "You are not an MP. Only MPs can claim this benefit.", []*discordgo.MessageEmbedField{})
+				return
+			}
+			duration := time.Since(cheese_user.LastPay)
+			if duration.Hours() < 15 {
+				create_embed("Rollcall", data_handler.session, data_handler.interaction, fmt.Sprint("You can claim this benefit only once per day. You have last claimed it ", duration.Round(time.Second).String(), " ago"),
+					[]*discordgo.MessageEmbedField{})
+				return
+			} else if duration.Hours() > 33 {
+				cheese_user.PayStreak = 0
+			}
+
+			cheese_user.LastPay = time.Now()
+
+			sucsess, err, _, _ := transaction(int(math.Min(5., math.Pow(1.1, float64(cheese_user.PayStreak)))*100), data.OrganisationAccounts[treasury], data.PersonalAccounts[cheese_user.PersonalAccount], "Treasury", data_handler.session, data_handler.interaction)
+
+			cheese_user.PayStreak += 1
+
+			if !sucsess {
+				create_embed("Rollcall", data_handler.session, data_handler.interaction, err, []*discordgo.MessageEmbedField{})
+			}
+		},
+		"rename_org": func(data_handler HandlerData) {
+			// Get the organisation
+			organisation := data_handler.interaction_data.Options[0].StringValue()
+			organisation_account := data.OrganisationAccounts[organisation]
+			organisation_name := organisation_account.Name
 
-			if amount > cheese_account.Balance {
-				create_embed("Gamble", data_handler.session, data_handler.interaction, "**ERROR:** You do not have enough funds.", []*discordgo.MessageEmbedField{})
+			if !user_has_org(data_handler.user, organisation, RoleOwner) {
+				create_embed("Rename organisation", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** You do not own ", organisation_name), []*discordgo.MessageEmbedField{})
 				return
 			}
 
-			winnings := int(float64(amount) * (data.CasinoReturns - 1))
+			new_name, err := validate_org_name(data_handler.interaction_data.Options[1].StringValue(), organisation)
+			if err != "" {
+				create_embed("Rename organisation", data_handler.session, data_handler.interaction, err, []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			organisation_account.Name = new_name
 
-			if winnings > data.OrganisationAccounts[casino].Balance {
-				create_embed("Gamble", data_handler.session, data_handler.interaction, "**ERROR:** The casino does not have enough funds.", []*discordgo.MessageEmbedField{})
+			create_embed("Rename organisation", data_handler.session, data_handler.interaction, fmt.Sprint(
+				"Sucessfully renamed ", organisation_name, " to ", new_name), []*discordgo.MessageEmbedField{})
+		},
+		"delete_org": func(data_handler HandlerData) {
+			// Get the organisation
+			organisation := data_handler.interaction_data.Options[0].StringValue()
+			organisation_account, ok := data.OrganisationAccounts[organisation]
+			if !ok || !user_has_org(data_handler.user, organisation, RoleOwner) {
+				create_embed("Delete organisation", data_handler.session, data_handler.interaction, "**ERROR:** You do not own that organisation", []*discordgo.MessageEmbedField{})
 				return
 			}
 
-			// Get the dice
-			predicted_dice := int(data_handler.interaction_data.Options[1].IntValue())
-			actual_dice := rand.Intn(5) + 1
+			if err := check_org_deletion(organisation, data_handler.user.ID); err != "" {
+				create_embed("Delete organisation", data_handler.session, data_handler.interaction, err, []*discordgo.MessageEmbedField{})
+				return
+			}
 
-			title := "Gambling Loss"
-			description := ""
-			if predicted_dice == actual_dice {
-				title = "Gambling Victory"
+			create_buttons("Delete organisation", data_handler.session, data_handler.interaction, fmt.Sprint("Are you sure you want to delete ", organisation_account.Name, "? Its balance of ", format_cheesecoins(organisation_account.Balance),
+				" will be transfered to your personal account, any open share orders will be cancelled and the organisation will be archived. This cannot be undone."),
+				[]discordgo.MessageComponent{
+					discordgo.Button{Label: "Delete", Style: discordgo.DangerButton, CustomID: "delete_org_confirm:" + organisation},
+					discordgo.Button{Label: "Cancel", Style: discordgo.SecondaryButton, CustomID: "delete_org_cancel:" + organisation},
+				})
+		},
+		"economy": func(data_handler HandlerData) {
+			current := economy_snapshot()
+			week := snapshot_before(time.Now().AddDate(0, 0, -7))
+			month := snapshot_before(time.Now().AddDate(0, 0, -30))
+
+			description := fmt.Sprintf("```\n%-20s %12s %10s %10s", "", "Current", "7 days", "30 days")
+			for _, metric := range economy_metrics {
+				description += format_economy_row(metric.Name+":", current, week, month, metric.Value)
+			}
+			description += "\n```\nVelocity is the daily transaction volume divided by the money supply. Active accounts have used the bot in the last 7 days."
+
+			create_embed("Economy", data_handler.session, data_handler.interaction, description, []*discordgo.MessageEmbedField{})
+		},
+		"chart": func(data_handler HandlerData) {
+			subcommand := data_handler.interaction_data.Options[0]
+
+			// Get the options - the default being the current user's personal account over the last 30 days
+			account_id := data.Users[data_handler.user.ID].PersonalAccount
+			period := 30
+			metric := economy_metrics[0]
+			for _, option := range subcommand.Options {
+				switch option.Name {
+				case "account":
+					account_id = option.StringValue()
+				case "period":
+					period = int(option.IntValue())
+				case "metric":
+					for _, m := range economy_metrics {
+						if m.Id == option.StringValue() {
+							metric = m
+						}
+					}
+				}
+			}
+
+			snapshots := []*EconomySnapshot{}
+			for _, snapshot := range data.Snapshots {
+				if time.Since(snapshot.Time) < time.Hour*24*time.Duration(period) {
+					snapshots = append(snapshots, snapshot)
+				}
+			}
+
+			name := ""
+			values := []float64{}
+			if subcommand.Name == "balance" {
+				account, ok := get_account(account_id)
+				if !ok {
+					create_embed("Chart", data_handler.session, data_handler.interaction, "**ERROR:** That account does not exist.", []*discordgo.MessageEmbedField{})
+					return
+				}
+				if account_id != data.Users[data_handler.user.ID].PersonalAccount && !user_has_org(data_handler.user, account_id, RoleViewer) && !data.Users[data_handler.user.ID].SuperUser {
+					create_embed("Chart", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** You do not own ", account.Name), []*discordgo.MessageEmbedField{})
+					return
+				}
 
-				description = fmt.Sprint("You predicted a 🎲", predicted_dice, " and the computer rolled a 🎲", actual_dice, ". You have won ", format_cheesecoins(winnings), " which will be transfered to your account shortly.")
-				transaction(winnings, data.OrganisationAccounts[casino], cheese_account, "Casino", data_handler.session, nil)
-				send_embed("Casino payout", data_handler.session, account_owner(data.OrganisationAccounts[casino]), fmt.Sprint(cheese_account.Name, " has won ", format_cheesecoins(winnings), " at your casino."), []*discordgo.MessageEmbedField{})
+				name = account.Name + " Balance"
+				for _, snapshot := range snapshots {
+					if balance, ok := snapshot.Balances[account_id]; ok {
+						values = append(values, float64(balance)/100)
+					}
+				}
+				values = append(values, float64(account.Balance)/100)
 			} else {
-				description = fmt.Sprint("You predicted a 🎲", predicted_dice, " and the computer rolled a 🎲", actual_dice, ". You have lost ", format_cheesecoins(amount), ".")
-				transaction(amount, cheese_account, data.OrganisationAccounts[casino], cheese_account.Name, data_handler.session, nil)
+				name = metric.Name
+				for _, snapshot := range snapshots {
+					values = append(values, metric.Value(snapshot))
+				}
+				values = append(values, metric.Value(economy_snapshot()))
 			}
 
-			create_embed(title, data_handler.session, data_handler.interaction, description, []*discordgo.MessageEmbedField{})
+			chart, err := render_chart(values)
+			if err != nil {
+				create_embed("Chart", data_handler.session, data_handler.interaction, "**ERROR:** Could not draw the chart.", []*discordgo.MessageEmbedField{})
+				return
+			}
 
+			min, max := chart_range(values)
+			description := fmt.Sprintf("**%s** over the last %d days (%d points).\n```\n%-10s %.2f\n%-10s %.2f\n%-10s %.2f\n%-10s %.2f\n```",
+				name, period, len(values), "Top:", max, "Bottom:", min, "Start:", values[0], "Now:", values[len(values)-1])
+
+			create_image_embed("Chart", data_handler.session, data_handler.interaction, description, chart)
 		},
-		"gambling_set_returns": func(data_handler HandlerData) {
-			if !user_has_org(data_handler.user, casino, false) {
-				create_embed("Gambling Set Returns", data_handler.session, data_handler.interaction, "**ERROR:** You are not the casino owner.", []*discordgo.MessageEmbedField{})
+		"sudo_set_wealth_tax": func(data_handler HandlerData) {
+			if !data.Users[data_handler.user.ID].SuperUser {
+				create_embed("Set Wealth Tax", data_handler.session, data_handler.interaction, "**ERROR:** You are not a super user", []*discordgo.MessageEmbedField{})
 				return
 			}
 
-			data.CasinoReturns = data_handler.interaction_data.Options[0].Value.(float64)
+			set_rate(data_handler, "Set Wealth Tax", RateWealthTax)
+		},
+		"sudo_set_transaction_tax": func(data_handler HandlerData) {
+			if !data.Users[data_handler.user.ID].SuperUser {
+				create_embed("Set Transaction Tax", data_handler.session, data_handler.interaction, "**ERROR:** You are not a super user", []*discordgo.MessageEmbedField{})
+				return
+			}
 
-			create_embed("Gambling Set Returns", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully set gambling returns to ", data.CasinoReturns, "."), []*discordgo.MessageEmbedField{})
+			set_rate(data_handler, "Set Transaction Tax", RateTransactionTax)
 		},
-	}
-	commandAutocomplete = map[string][]int8{
-		"help":                     {},
-		"balances":                 {},
+		"sudo_set_winnings_tax": func(data_handler HandlerData) {
+			if !data.Users[data_handler.user.ID].SuperUser {
+				create_embed("Set Winnings Tax", data_handler.session, data_handler.interaction, "**ERROR:** You are not a super user", []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			set_rate(data_handler, "Set Winnings Tax", RateWinningsTax)
+		},
+		"sudo_set_lottery_tax": func(data_handler HandlerData) {
+			if !data.Users[data_handler.user.ID].SuperUser {
+				create_embed("Set Lottery Tax", data_handler.session, data_handler.interaction, "**ERROR:** You are not a super user", []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			set_rate(data_handler, "Set Lottery Tax", RateLotteryTax)
+		},
+		"sudo_set_sales_tax": func(data_handler HandlerData) {
+			if !data.Users[data_handler.user.ID].SuperUser {
+				create_embed("Set Sales Tax", data_handler.session, data_handler.interaction, "**ERROR:** You are not a super user", []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			set_rate(data_handler, "Set Sales Tax", RateSalesTax)
+		},
+		"sudo_set_bank_holiday": func(data_handler HandlerData) {
+			if !data.Users[data_handler.user.ID].BankHolidaySetter {
+				create_embed("Set Bank Holiday", data_handler.session, data_handler.interaction, "**ERROR:** You are not a user eligible to set bank holidays", []*discordgo.MessageEmbedField{})
+				return
+			}
+			day := data_handler.interaction_data.Options[0].IntValue()
+			month := data_handler.interaction_data.Options[1].IntValue()
+			enabled := data_handler.interaction_data.Options[2].BoolValue()
+			holiday := month<<12 + day
+			contains_time := contains_int64(data.BankHolidays, holiday)
+
+			result := ""
+			if enabled {
+				if contains_time {
+					result = "is already a bank holiday"
+
+				} else {
+					result = "is now a bank holiday"
+					data.BankHolidays = append(data.BankHolidays, holiday)
+				}
+			} else {
+				if contains_time {
+					result = "is no longer a bank holiday"
+					data.BankHolidays = remove_int64(data.BankHolidays, holiday)
+				} else {
+					result = "was already not a bank holiday"
+				}
+
+			}
+
+			create_embed("Set Bank Holiday", data_handler.session, data_handler.interaction, fmt.Sprint(format_date(holiday), " ", result, "."), []*discordgo.MessageEmbedField{})
+		},
+		"bank_holidays": func(data_handler HandlerData) {
+
+			result := ""
+			if len(data.BankHolidays) > 0 {
+				for _, t := range data.BankHolidays {
+					result += fmt.Sprint("\nBank holiday on ", format_date(t), " ", result)
+				}
+				fmt.Print("result: ", result)
+			} else {
+				result += "No Bank holidays."
+			}
+
+			create_embed("Bank Holidays", data_handler.session, data_handler.interaction, result, []*discordgo.MessageEmbedField{})
+		},
+		"sudo_set_ubi": func(data_handler HandlerData) {
+			if !data.Users[data_handler.user.ID].SuperUser {
+				create_embed("Set UBI", data_handler.session, data_handler.interaction, "**ERROR:** You are not a super user", []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			data.UbiAmount = cheesecoin_option(data_handler.interaction_data.Options[0])
+			data.UbiInterval = int(data_handler.interaction_data.Options[1].IntValue())
+			data.UbiActiveDays = int(data_handler.interaction_data.Options[2].IntValue())
+
+			if data.UbiAmount == 0 {
+				create_embed("Set UBI", data_handler.session, data_handler.interaction, "Universal basic income has been disabled.", []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			create_embed("Set UBI", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully set universal basic income to ", format_cheesecoins(data.UbiAmount), " every ", data.UbiInterval,
+				" day(s) for everyone active in the last ", data.UbiActiveDays, " day(s)."), []*discordgo.MessageEmbedField{})
+		},
+		"sudo_set_org_fee": func(data_handler HandlerData) {
+			if !data.Users[data_handler.user.ID].SuperUser {
+				create_embed("Set Organisation Fee", data_handler.session, data_handler.interaction, "**ERROR:** You are not a super user", []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			data.OrgCreationFee = cheesecoin_option(data_handler.interaction_data.Options[0])
+
+			create_embed("Set Organisation Fee", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully set the organisation creation fee to ", format_cheesecoins(data.OrgCreationFee), "."), []*discordgo.MessageEmbedField{})
+		},
+		"sudo_loan": func(data_handler HandlerData) {
+			if !user_has_org(data_handler.user, bank, RoleTreasurer) {
+				create_embed("Loan", data_handler.session, data_handler.interaction, "**ERROR:** You are not a super user", []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			recipiant := data_handler.interaction_data.Options[0].StringValue()
+			recipiant_account, _ := get_account(recipiant)
+
+			// Get the transaction amount
+			amount := cheesecoin_option(data_handler.interaction_data.Options[1])
+
+			sucsess, result, _, _ := transaction(amount, data.OrganisationAccounts[bank], recipiant_account, "The Bank", data_handler.session, nil)
+
+			if sucsess {
+				result = fmt.Sprint("A ", format_cheesecoins(amount), " loan has been granted to ", recipiant_account.Name, " with an interest rate of ", fmt.Sprintf("%.2f%%", data.LoanInterest), ".")
+				recipiant_account.Loans = append(recipiant_account.Loans, &Loan{Start: time.Now(), AmountDue: int(math.Ceil(float64(amount) * (data.LoanInterest + 100) / 100)), LoanValue: amount})
+			}
+
+			create_embed("Loan", data_handler.session, data_handler.interaction, result, []*discordgo.MessageEmbedField{})
+		},
+		"sudo_set_interest_rate": func(data_handler HandlerData) {
+			if !user_has_org(data_handler.user, bank, RoleOwner) {
+				create_embed("Set Interest Rate", data_handler.session, data_handler.interaction, "**ERROR:** You are not a super user", []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			set_rate(data_handler, "Set Interest Rate", RateInterest)
+		},
+		"rates_history": func(data_handler HandlerData) {
+			result := "**Rate changes**"
+			history := data.RateHistory
+			if len(history) > 20 {
+				history = history[len(history)-20:]
+			}
+			for _, change := range history {
+				result += fmt.Sprint("\n<t:", change.Effective.Unix(), ":d> ", change.Rate, " changed from ", fmt.Sprintf("%.2f%% to %.2f%%", change.OldValue, change.NewValue), " by ", format_user(change.User))
+				if !same_day(change.Time, change.Effective) {
+					result += fmt.Sprint(" (scheduled <t:", change.Time.Unix(), ":d>)")
+				}
+			}
+			if len(history) == 0 {
+				result += "\nNo rate changes."
+			}
+
+			if len(data.ScheduledRates) > 0 {
+				result += "\n\n**Scheduled changes**"
+				for _, change := range data.ScheduledRates {
+					result += fmt.Sprint("\n<t:", change.Effective.Unix(), ":d> ", change.Rate, " will change to ", fmt.Sprintf("%.2f%%", change.NewValue), " (scheduled by ", format_user(change.User), " <t:", change.Time.Unix(), ":d>)")
+				}
+			}
+
+			create_embed("Rates History", data_handler.session, data_handler.interaction, result, []*discordgo.MessageEmbedField{})
+		},
+		"view_bank_loans": func(data_handler HandlerData) {
+			cheese_user := data.Users[data_handler.user.ID]
+
+			result := "**Your loans:**"
+			r, any_loans := format_loans(data.PersonalAccounts[cheese_user.PersonalAccount])
+			result += r
+			for _, org := range cheese_user.Organisations {
+				r, loan := format_loans(data.OrganisationAccounts[org])
+				result += r
+				if loan {
+					any_loans = true
+				}
+			}
+			if !any_loans {
+				result += "\nNo loans."
+			}
+
+			if user_has_org(data_handler.user, bank, RoleViewer) {
+				result += "\n\n**All loans:**"
+				for _, acc := range data.PersonalAccounts {
+					r, loan := format_loans(acc)
+					result += r
+					if loan {
+						any_loans = true
+					}
+				}
+				for _, acc := range data.OrganisationAccounts {
+					r, loan := format_loans(acc)
+					result += r
+					if loan {
+						any_loans = true
+					}
+				}
+				if !any_loans {
+					result += "\nNo loans."
+				}
+			}
+
+			create_embed("View Loans", data_handler.session, data_handler.interaction, result, []*discordgo.MessageEmbedField{})
+		},
+		"gamble": func(data_handler HandlerData) {
+			// Get the transaction amount
+			amount := cheesecoin_option(data_handler.interaction_data.Options[0])
+			winnings := int(float64(amount) * (data.CasinoReturns - 1))
+
+			cheese_account := data.PersonalAccounts[data.Users[data_handler.user.ID].PersonalAccount]
+
+			if err := check_gambling(data_handler.user.ID, amount); err != "" {
+				create_embed("Gamble", data_handler.session, data_handler.interaction, err, []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			// Hold the stake and the winnings in escrow until the dice is rolled
+			if err := reserve_bet(data_handler.user.ID, amount, winnings); err != "" {
+				create_embed("Gamble", data_handler.session, data_handler.interaction, err, []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			// Get the dice
+			predicted_dice := int(data_handler.interaction_data.Options[1].IntValue())
+			roll, nonce := casino_roll(data_handler.user.ID, "Dice", 6)
+			actual_dice := roll + 1
+
+			payout := 0
+			if predicted_dice == actual_dice {
+				payout = amount + winnings
+			}
+			tax, err := settle_bet(data_handler.session, data_handler.user.ID, amount, winnings, payout)
+			record_gambling_result(data_handler.user.ID, payout-amount)
+			record_casino_bet(data_handler.user.ID, "Dice", amount, payout, dice_edge())
+
+			title := "Gambling Loss"
+			description := ""
+			if predicted_dice == actual_dice {
+				title = "Gambling Victory"
+
+				description = fmt.Sprint("You predicted a 🎲", predicted_dice, " and the computer rolled a 🎲", actual_dice, ". You have won ", format_cheesecoins(winnings), format_winnings_tax(tax), ".")
+				notify_casino_win(data_handler.session, cheese_account.Name, winnings, "dice")
+			} else {
+				description = fmt.Sprint("You predicted a 🎲", predicted_dice, " and the computer rolled a 🎲", actual_dice, ". You have lost ", format_cheesecoins(amount), ".")
+			}
+			if err != "" {
+				description += "\n\n" + err
+			}
+
+			description += fmt.Sprint("\n\nThis was roll #", nonce, " of your current seeds. See /casino_seed to check it.")
+
+			create_embed(title, data_handler.session, data_handler.interaction, description, []*discordgo.MessageEmbedField{})
+
+		},
+		"casino_seed": func(data_handler HandlerData) {
+			description := ""
+			if len(data_handler.interaction_data.Options) > 0 {
+				old := rotate_casino_seed(data_handler.user.ID, data_handler.interaction_data.Options[0].StringValue())
+				description = fmt.Sprintf("Your old seeds have been retired. Use them with /casino_verify to check your rolls.\n```\n%-18s %s\n%-18s %s\n%-18s %s\n%-18s %d\n```\n",
+					"Server seed:", old.ServerSeed, "Server seed hash:", hash_seed(old.ServerSeed), "Client seed:", old.ClientSeed, "Rolls made:", old.Nonce)
+			}
+
+			seed := casino_seed(data_handler.user.ID)
+			description += fmt.Sprintf("Your current seeds are\n```\n%-18s %s\n%-18s %s\n%-18s %d\n```\nThe server seed is kept secret until you set a new client seed, but its hash cannot change.",
+				"Server seed hash:", hash_seed(seed.ServerSeed), "Client seed:", seed.ClientSeed, "Next roll:", seed.Nonce)
+
+			create_embed("Casino seeds", data_handler.session, data_handler.interaction, description, []*discordgo.MessageEmbedField{})
+		},
+		"casino_verify": func(data_handler HandlerData) {
+			server_seed := data_handler.interaction_data.Options[0].StringValue()
+			client_seed := data_handler.interaction_data.Options[1].StringValue()
+			nonce := int(data_handler.interaction_data.Options[2].IntValue())
+			sides := 6
+			if len(data_handler.interaction_data.Options) > 3 {
+				sides = int(data_handler.interaction_data.Options[3].IntValue())
+			}
+
+			server_seed_hash := hash_seed(server_seed)
+			if seed, ok := data.CasinoSeeds[data_handler.user.ID]; ok && seed.ServerSeed == server_seed {
+				create_embed("Verify casino roll", data_handler.session, data_handler.interaction, "**ERROR:** That server seed is still in use. Set a new client seed with /casino_seed to reveal it.", []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			result := fair_roll(server_seed, client_seed, nonce, sides)
+			description := fmt.Sprint("The server seed has the hash `", server_seed_hash, "`.\nWith these seeds roll #", nonce, " of ", sides, " sides is **", result, "** (a 🎲", result+1, " for dice).")
+
+			if roll := find_casino_roll(server_seed_hash, client_seed, nonce); roll != nil {
+				if roll.Sides == sides && roll.Result == result {
+					description += fmt.Sprint("\n\n✅ This matches the ", roll.Game, " roll made for ", format_roll_user(roll), " <t:", roll.Time.Unix(), ":R>.")
+				} else if roll.Sides != sides {
+					description += fmt.Sprint("\n\nThe ", roll.Game, " roll made with these seeds had ", roll.Sides, " sides. Verify it with that number of sides.")
+				} else {
+					description += fmt.Sprint("\n\n❌ This does not match the ", roll.Game, " roll of ", roll.Result, " made for ", format_roll_user(roll), " <t:", roll.Time.Unix(), ":R>.")
+				}
+			} else {
+				description += "\n\nNo recorded roll was made with these seeds, so check the hash against the one you were shown."
+			}
+
+			create_embed("Verify casino roll", data_handler.session, data_handler.interaction, description, []*discordgo.MessageEmbedField{})
+		},
+		"blackjack": func(data_handler HandlerData) {
+			stake := cheesecoin_option(data_handler.interaction_data.Options[0])
+
+			err, id := start_blackjack(data_handler.user.ID, stake)
+			if err != "" {
+				create_embed("Blackjack", data_handler.session, data_handler.interaction, err, []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			// The game is over straight away if either hand is a natural blackjack
+			game := data.BlackjackGames[id]
+			if is_blackjack(game.Player) || is_blackjack(game.Dealer) {
+				create_embed("Blackjack", data_handler.session, data_handler.interaction, settle_blackjack(data_handler.session, id), []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			create_buttons("Blackjack", data_handler.session, data_handler.interaction, fmt.Sprint(format_blackjack(game, true), "\n\nThe game expires <t:", game.Expires.Unix(), ":R>."), blackjack_buttons(id))
+		},
+		"blackjack_rules": func(data_handler HandlerData) {
+			if !user_has_org(data_handler.user, casino, RoleOwner) {
+				create_embed("Blackjack Rules", data_handler.session, data_handler.interaction, "**ERROR:** You are not the casino owner.", []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			data.BlackjackRules = &BlackjackRules{
+				Decks:         int(data_handler.interaction_data.Options[0].IntValue()),
+				HitSoft17:     data_handler.interaction_data.Options[1].BoolValue(),
+				BlackjackPays: data_handler.interaction_data.Options[2].FloatValue(),
+			}
+
+			hit_soft_17 := "stands"
+			if data.BlackjackRules.HitSoft17 {
+				hit_soft_17 = "hits"
+			}
+			create_embed("Blackjack Rules", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully set the blackjack rules. Games use ", data.BlackjackRules.Decks, " deck(s), the dealer ", hit_soft_17,
+				" on soft 17 and blackjack pays ", data.BlackjackRules.BlackjackPays, " times the stake. Games already in play use the new rules from now on."), []*discordgo.MessageEmbedField{})
+		},
+		"roulette": func(data_handler HandlerData) {
+			bets, err := parse_roulette_bets(data_handler.interaction_data.Options[0].StringValue())
+			if err != "" {
+				create_embed("Roulette", data_handler.session, data_handler.interaction, err, []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			cheese_account := data.PersonalAccounts[data.Users[data_handler.user.ID].PersonalAccount]
+			total_stake := 0
+			for _, bet := range bets {
+				total_stake += bet.Stake
+			}
+			if err := check_gambling(data_handler.user.ID, total_stake); err != "" {
+				create_embed("Roulette", data_handler.session, data_handler.interaction, err, []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			// Hold the stakes and the worst case payout in escrow until the wheel is spun
+			cover := roulette_worst_case(bets)
+			if err := reserve_bet(data_handler.user.ID, total_stake, cover); err != "" {
+				create_embed("Roulette", data_handler.session, data_handler.interaction, err, []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			number, nonce := casino_roll(data_handler.user.ID, "Roulette", 37)
+
+			description := fmt.Sprint("The ball landed on ", format_roulette_number(number), ".\n```")
+			for _, bet := range bets {
+				result := "Lost"
+				if roulette_wins(bet, number) {
+					result = "Won " + format_cheesecoins(roulette_winnings(bet))
+				}
+				description += fmt.Sprintf("\n%-12s %12s   %s", format_roulette_bet(bet), format_cheesecoins(bet.Stake), result)
+			}
+			description += "\n```"
+
+			title := "Roulette Loss"
+			payout := roulette_payout(bets, number)
+			tax, err := settle_bet(data_handler.session, data_handler.user.ID, total_stake, cover, total_stake+payout)
+			record_gambling_result(data_handler.user.ID, payout)
+			record_casino_bet(data_handler.user.ID, "Roulette", total_stake, total_stake+payout, roulette_edge(bets))
+			if payout > 0 {
+				title = "Roulette Victory"
+				description += fmt.Sprint("\nYou have won ", format_cheesecoins(payout), " overall", format_winnings_tax(tax), ".")
+				notify_casino_win(data_handler.session, cheese_account.Name, payout, "roulette")
+			} else if payout < 0 {
+				description += fmt.Sprint("\nYou have lost ", format_cheesecoins(-payout), " overall.")
+			} else {
+				title = "Roulette"
+				description += "\nYou have broken even."
+			}
+			if err != "" {
+				description += "\n\n" + err
+			}
+			description += fmt.Sprint("\n\nThis was roll #", nonce, " of your current seeds. See /casino_seed to check it.")
+
+			create_embed(title, data_handler.session, data_handler.interaction, description, []*discordgo.MessageEmbedField{})
+		},
+		"roulette_set_edge": func(data_handler HandlerData) {
+			if !user_has_org(data_handler.user, casino, RoleOwner) {
+				create_embed("Roulette Set Edge", data_handler.session, data_handler.interaction, "**ERROR:** You are not the casino owner.", []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			data.RouletteHouseEdge = data_handler.interaction_data.Options[0].FloatValue()
+
+			create_embed("Roulette Set Edge", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully set the roulette house edge to ", data.RouletteHouseEdge, "%. Winnings are now ",
+				100-data.RouletteHouseEdge, "% of the standard odds."), []*discordgo.MessageEmbedField{})
+		},
+		"lottery": func(data_handler HandlerData) {
+			subcommand := data_handler.interaction_data.Options[0]
+			round := open_lottery()
+
+			switch subcommand.Name {
+			case "start":
+				if !user_has_org(data_handler.user, casino, RoleOwner) {
+					create_embed("Lottery", data_handler.session, data_handler.interaction, "**ERROR:** You are not the casino owner.", []*discordgo.MessageEmbedField{})
+					return
+				}
+				if round != nil {
+					create_embed("Lottery", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** Round ", round.Id, " is still open until <t:", round.Draw.Unix(), ":f>."), []*discordgo.MessageEmbedField{})
+					return
+				}
+
+				ticket_price := cheesecoin_option(subcommand.Options[0])
+				draw := time.Now().Add(time.Hour * time.Duration(subcommand.Options[1].IntValue()))
+				winners, casino_cut := 1, 10.0
+				for _, option := range subcommand.Options[2:] {
+					switch option.Name {
+					case "winners":
+						winners = int(option.IntValue())
+					case "casino_cut":
+						casino_cut = option.FloatValue()
+					}
+				}
+				if casino_cut+data.LotteryTax > 100 {
+					create_embed("Lottery", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** The casino cut and the lottery tax of ", data.LotteryTax, "% cannot add up to more than 100%."), []*discordgo.MessageEmbedField{})
+					return
+				}
+
+				round = start_lottery(ticket_price, draw, winners, casino_cut)
+				description := fmt.Sprint("Round ", round.Id, " of the lottery is open! Tickets cost ", format_cheesecoins(ticket_price), " each and the draw is <t:", draw.Unix(), ":R>. The prize pool is shared between ", winners,
+					" winning ticket(s) after ", casino_cut, "% to the casino and ", data.LotteryTax, "% lottery tax. Buy tickets with /lottery buy.")
+				// Reply before the round is announced to every user, which queues a message for each of them
+				create_embed("Lottery", data_handler.session, data_handler.interaction, "Sucessfully started the lottery. "+description, []*discordgo.MessageEmbedField{})
+				for id := range data.Users {
+					send_embed("Lottery", data_handler.session, id, description, []*discordgo.MessageEmbedField{})
+				}
+			case "buy":
+				if round == nil {
+					create_embed("Lottery", data_handler.session, data_handler.interaction, "**ERROR:** There is no lottery open at the moment.", []*discordgo.MessageEmbedField{})
+					return
+				}
+
+				tickets := int(subcommand.Options[0].IntValue())
+				cheese_account := data.PersonalAccounts[data.Users[data_handler.user.ID].PersonalAccount]
+				if tickets*round.TicketPrice > cheese_account.Balance {
+					create_embed("Lottery", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** ", tickets, " tickets cost ", format_cheesecoins(tickets*round.TicketPrice), " but you have only ", format_cheesecoins(cheese_account.Balance), "."), []*discordgo.MessageEmbedField{})
+					return
+				}
+				if err := check_gambling(data_handler.user.ID, tickets*round.TicketPrice); err != "" {
+					create_embed("Lottery", data_handler.session, data_handler.interaction, err, []*discordgo.MessageEmbedField{})
+					return
+				}
+				// The casino never pays out more than the pool, so no cover is needed
+				if err := reserve_bet(data_handler.user.ID, tickets*round.TicketPrice, 0); err != "" {
+					create_embed("Lottery", data_handler.session, data_handler.interaction, err, []*discordgo.MessageEmbedField{})
+					return
+				}
+				record_gambling_result(data_handler.user.ID, -tickets*round.TicketPrice)
+				round.Tickets[data_handler.user.ID] += tickets
+
+				create_embed("Lottery", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully bought ", tickets, " ticket(s) for ", format_cheesecoins(tickets*round.TicketPrice), ". You now have ", round.Tickets[data_handler.user.ID],
+					" of the ", lottery_tickets(round), " tickets in round ", round.Id, ", which is drawn <t:", round.Draw.Unix(), ":R>."), []*discordgo.MessageEmbedField{})
+			case "status":
+				description := ""
+				if round != nil {
+					description = fmt.Sprintf("**Round %s**\n```\n%-20s %s\n%-20s %d\n%-20s %s\n%-20s %d\n%-20s %d\n%-20s %.2f%%\n%-20s %.2f%%\n```\nDrawn <t:%d:R>. The server seed hash is `%s`.\n",
+						round.Id, "Ticket price:", format_cheesecoins(round.TicketPrice), "Tickets sold:", lottery_tickets(round), "Prize pool:", format_cheesecoins(lottery_tickets(round)*round.TicketPrice),
+						"Your tickets:", round.Tickets[data_handler.user.ID], "Winning tickets:", round.Winners, "Casino cut:", round.CasinoCut, "Lottery tax:", round.LotteryTax, round.Draw.Unix(), round.SeedHash)
+				} else {
+					description = "There is no lottery open at the moment.\n"
+				}
+
+				// Show the results of the last draw
+				for i := len(data.LotteryRounds) - 1; i >= 0; i-- {
+					if previous := data.LotteryRounds[i]; previous.Drawn {
+						description += fmt.Sprint("\n**Round ", previous.Id, " results** (drawn <t:", previous.Draw.Unix(), ":D>)")
+						for winner, prize := range previous.Prizes {
+							description += fmt.Sprint("\n", format_user(winner), " won ", format_cheesecoins(prize))
+						}
+						if len(previous.Prizes) == 0 {
+							description += "\nNo tickets were sold."
+						}
+						break
+					}
+				}
+
+				create_embed("Lottery", data_handler.session, data_handler.interaction, description, []*discordgo.MessageEmbedField{})
+			}
+		},
+		"wager": func(data_handler HandlerData) {
+			subcommand := data_handler.interaction_data.Options[0]
+
+			switch subcommand.Name {
+			case "propose":
+				counterparty := subcommand.Options[0].StringValue()
+				if _, ok := data.Users[counterparty]; !ok || counterparty == data_handler.user.ID {
+					create_embed("Wager", data_handler.session, data_handler.interaction, "**ERROR:** You must bet against another user of the cheese bot", []*discordgo.MessageEmbedField{})
+					return
+				}
+				amount := cheesecoin_option(subcommand.Options[1])
+				terms := strings.TrimSpace(subcommand.Options[2].StringValue())
+				if terms == "" || utf8.RuneCountInString(terms) > MaxWagerTerms {
+					create_embed("Wager", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** The terms must be between 1 and ", MaxWagerTerms, " characters"), []*discordgo.MessageEmbedField{})
+					return
+				}
+
+				arbiter, days := "", 7
+				for _, option := range subcommand.Options[3:] {
+					switch option.Name {
+					case "arbiter":
+						arbiter = option.StringValue()
+					case "days":
+						days = int(option.IntValue())
+					}
+				}
+				if _, ok := data.Users[arbiter]; arbiter != "" && (!ok || arbiter == data_handler.user.ID || arbiter == counterparty) {
+					create_embed("Wager", data_handler.session, data_handler.interaction, "**ERROR:** The arbiter must be a user of the cheese bot who is not taking part in the wager", []*discordgo.MessageEmbedField{})
+					return
+				}
+
+				cheese_account := data.PersonalAccounts[data.Users[data_handler.user.ID].PersonalAccount]
+				if amount > cheese_account.Balance {
+					create_embed("Wager", data_handler.session, data_handler.interaction, "**ERROR:** You do not have enough funds.", []*discordgo.MessageEmbedField{})
+					return
+				}
+				cheese_account.Balance -= amount
+				data.Escrow.Balance += amount
+
+				id := fmt.Sprint(data.NextWager)
+				data.NextWager += 1
+				wager := &Wager{Proposer: data_handler.user.ID, Counterparty: counterparty, Arbiter: arbiter, Amount: amount, Terms: terms, Days: days,
+					Expires: time.Now().Add(WagerOfferTimeout), Votes: map[string]string{}}
+				data.Wagers[id] = wager
+
+				send_buttons("Wager offer", data_handler.session, counterparty, fmt.Sprint(format_user(wager.Proposer), " would like to bet you ", format_cheesecoins(amount), " each on:\n> ", terms,
+					"\n\nAccepting holds your stake in escrow until the wager is decided. The offer expires <t:", wager.Expires.Unix(), ":R>."),
+					[]discordgo.MessageComponent{
+						discordgo.Button{Label: "Accept", Style: discordgo.SuccessButton, CustomID: "wager_accept:" + id},
+						discordgo.Button{Label: "Decline", Style: discordgo.DangerButton, CustomID: "wager_decline:" + id},
+					})
+
+				create_embed("Wager", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully proposed the wager. Your stake of ", format_cheesecoins(amount), " is held in escrow until ", format_user(counterparty),
+					" accepts, or refunded if they do not accept <t:", wager.Expires.Unix(), ":R>."), []*discordgo.MessageEmbedField{})
+			case "list":
+				description := ""
+				for _, wager := range data.Wagers {
+					if wager.Proposer != data_handler.user.ID && wager.Counterparty != data_handler.user.ID && wager.Arbiter != data_handler.user.ID {
+						continue
+					}
+					description += fmt.Sprint("\n\n", format_wager(wager))
+					if !wager.Accepted {
+						description += fmt.Sprint("\nWaiting for ", format_user(wager.Counterparty), " to accept until <t:", wager.Expires.Unix(), ":f>.")
+					} else {
+						description += fmt.Sprint("\nRefunded <t:", wager.Deadline.Unix(), ":R> unless a winner is picked.")
+						for user, winner := range wager.Votes {
+							description += fmt.Sprint(" ", format_user(user), " says ", format_user(winner), " won.")
+						}
+					}
+				}
+				if description == "" {
+					description = "You are not taking part in any wagers."
+				}
+
+				create_embed("Wager", data_handler.session, data_handler.interaction, strings.TrimSpace(description), []*discordgo.MessageEmbedField{})
+			}
+		},
+		"escrow": func(data_handler HandlerData) {
+			subcommand := data_handler.interaction_data.Options[0]
+
+			switch subcommand.Name {
+			case "create":
+				recipiant := subcommand.Options[0].StringValue()
+				recipiant_account, ok := get_account(recipiant)
+				if !ok {
+					create_embed("Escrow", data_handler.session, data_handler.interaction, "**ERROR:** The recipiant does not exist", []*discordgo.MessageEmbedField{})
+					return
+				}
+				amount := cheesecoin_option(subcommand.Options[1])
+				terms := strings.TrimSpace(subcommand.Options[2].StringValue())
+				if terms == "" || utf8.RuneCountInString(terms) > MaxEscrowTerms {
+					create_embed("Escrow", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** The terms must be between 1 and ", MaxEscrowTerms, " characters"), []*discordgo.MessageEmbedField{})
+					return
+				}
+				days := int(subcommand.Options[3].IntValue())
+
+				// Get the payer - the default being the current user's personal account
+				payer := data.Users[data_handler.user.ID].PersonalAccount
+				payer_account, _ := get_account(payer)
+				if len(subcommand.Options) > 4 {
+					payer = subcommand.Options[4].StringValue()
+					if !user_has_org(data_handler.user, payer, RoleTreasurer) {
+						create_embed("Escrow", data_handler.session, data_handler.interaction, "**ERROR:** You are not a treasurer or owner of that organisation", []*discordgo.MessageEmbedField{})
+						return
+					}
+					payer_account = data.OrganisationAccounts[payer]
+
+					if err := check_spending_limit(payer_account, data_handler.user.ID, amount); err != "" {
+						create_embed("Escrow", data_handler.session, data_handler.interaction, err, []*discordgo.MessageEmbedField{})
+						return
+					}
+					if payer_account.ApprovalThreshold > 0 && amount > payer_account.ApprovalThreshold {
+						create_embed("Escrow", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** Payments over ", format_cheesecoins(payer_account.ApprovalThreshold), " from ", payer_account.Name, " need approval, so they cannot be held in escrow"), []*discordgo.MessageEmbedField{})
+						return
+					}
+				}
+				if payer == recipiant {
+					create_embed("Escrow", data_handler.session, data_handler.interaction, "**ERROR:** You cannot pay an account into escrow for itself", []*discordgo.MessageEmbedField{})
+					return
+				}
+				if amount > payer_account.Balance {
+					create_embed("Escrow", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** ", payer_account.Name, " has only ", format_cheesecoins(payer_account.Balance)), []*discordgo.MessageEmbedField{})
+					return
+				}
+				payer_account.Balance -= amount
+				data.Escrow.Balance += amount
+				record_spending(payer_account, data_handler.user.ID, amount)
+
+				id := fmt.Sprint(data.NextEscrowPayment)
+				data.NextEscrowPayment += 1
+				escrow := &EscrowPayment{Payer: payer, Recipiant: recipiant, Requester: data_handler.user.ID, Amount: amount, Terms: terms, Created: time.Now(), Deadline: time.Now().AddDate(0, 0, days)}
+				data.EscrowPayments[id] = escrow
+
+				for _, owner := range account_owners(recipiant_account) {
+					if owner != data_handler.user.ID {
+						send_embed("Escrow", data_handler.session, owner, fmt.Sprint(format_user(data_handler.user.ID), " has paid into escrow for you.\n\n", format_escrow(id, escrow),
+							"\n\nUse /escrow dispute if you have kept to the terms and it is not released."), []*discordgo.MessageEmbedField{})
+					}
+				}
+				create_embed("Escrow", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully paid into escrow. Use /escrow release once the terms have been met.\n\n", format_escrow(id, escrow)), []*discordgo.MessageEmbedField{})
+			case "release", "refund", "dispute":
+				id := subcommand.Options[0].StringValue()
+				escrow, ok := data.EscrowPayments[id]
+				if !ok {
+					create_embed("Escrow", data_handler.session, data_handler.interaction, "**ERROR:** That escrow payment does not exist or has already been settled", []*discordgo.MessageEmbedField{})
+					return
+				}
+				payer_side, recipiant_side := escrow_party(data_handler.user, escrow.Payer), escrow_party(data_handler.user, escrow.Recipiant)
+
+				switch subcommand.Name {
+				case "release":
+					if !payer_side {
+						create_embed("Escrow", data_handler.session, data_handler.interaction, "**ERROR:** Only the payer can release an escrow payment", []*discordgo.MessageEmbedField{})
+						return
+					}
+					create_embed("Escrow", data_handler.session, data_handler.interaction, release_escrow(data_handler.session, id, fmt.Sprint("It was released by ", format_user(data_handler.user.ID), ".")), []*discordgo.MessageEmbedField{})
+				case "refund":
+					if !recipiant_side {
+						create_embed("Escrow", data_handler.session, data_handler.interaction, "**ERROR:** Only the recipiant can give back an escrow payment", []*discordgo.MessageEmbedField{})
+						return
+					}
+					create_embed("Escrow", data_handler.session, data_handler.interaction, refund_escrow(data_handler.session, id, fmt.Sprint("It was given back by ", format_user(data_handler.user.ID), ".")), []*discordgo.MessageEmbedField{})
+				case "dispute":
+					if !payer_side && !recipiant_side {
+						create_embed("Escrow", data_handler.session, data_handler.interaction, "**ERROR:** You are not taking part in that escrow payment", []*discordgo.MessageEmbedField{})
+						return
+					}
+					if escrow.Disputed {
+						create_embed("Escrow", data_handler.session, data_handler.interaction, "**ERROR:** That escrow payment is already disputed", []*discordgo.MessageEmbedField{})
+						return
+					}
+					super_users := []string{}
+					for user, usr := range data.Users {
+						if usr.SuperUser {
+							super_users = append(super_users, user)
+						}
+					}
+					if len(super_users) == 0 {
+						create_embed("Escrow", data_handler.session, data_handler.interaction, "**ERROR:** There are no super users to decide a dispute", []*discordgo.MessageEmbedField{})
+						return
+					}
+
+					escrow.Disputed = true
+					escrow.DisputeReason = truncate(strings.TrimSpace(subcommand.Options[1].StringValue()), MaxEscrowTerms)
+					escrow.DisputeDeadline = time.Now().Add(EscrowDisputeTimeout)
+
+					description := fmt.Sprint(format_user(data_handler.user.ID), " has disputed an escrow payment.\n\n", format_escrow(id, escrow))
+					for _, user := range super_users {
+						send_buttons("Escrow dispute", data_handler.session, user, description, escrow_arbiter_buttons(id))
+					}
+					notify_escrow(data_handler.session, escrow, "Escrow dispute", description+"\n\nA super user will decide who gets the payment.")
+					create_embed("Escrow", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully disputed the escrow payment. A super user will decide who gets it, or it is refunded <t:", escrow.DisputeDeadline.Unix(), ":R>."), []*discordgo.MessageEmbedField{})
+				}
+			case "list":
+				description := ""
+				super_user := data.Users[data_handler.user.ID].SuperUser
+				for id, escrow := range data.EscrowPayments {
+					if escrow_party(data_handler.user, escrow.Payer) || escrow_party(data_handler.user, escrow.Recipiant) || (super_user && escrow.Disputed) {
+						description += "\n\n" + format_escrow(id, escrow)
+					}
+				}
+				if description == "" {
+					description = "You have no escrow payments."
+				}
+
+				create_embed("Escrow", data_handler.session, data_handler.interaction, strings.TrimSpace(description), []*discordgo.MessageEmbedField{})
+			}
+		},
+		"campaign": func(data_handler HandlerData) {
+			subcommand := data_handler.interaction_data.Options[0]
+
+			switch subcommand.Name {
+			case "create":
+				organisation := subcommand.Options[0].StringValue()
+				if !user_has_org(data_handler.user, organisation, RoleOwner) {
+					create_embed("Campaign", data_handler.session, data_handler.interaction, "**ERROR:** You do not own that organisation", []*discordgo.MessageEmbedField{})
+					return
+				}
+				title := strings.Join(strings.Fields(subcommand.Options[1].StringValue()), " ")
+				if title == "" || utf8.RuneCountInString(title) > MaxCampaignTitle {
+					create_embed("Campaign", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** The title must be between 1 and ", MaxCampaignTitle, " characters"), []*discordgo.MessageEmbedField{})
+					return
+				}
+				goal := cheesecoin_option(subcommand.Options[2])
+				days := int(subcommand.Options[3].IntValue())
+
+				id := fmt.Sprint(data.NextCampaign)
+				data.NextCampaign += 1
+				campaign := &Campaign{Organisation: organisation, Title: title, Goal: goal, Deadline: time.Now().AddDate(0, 0, days), Pledges: map[string]int{}}
+				data.Campaigns[id] = campaign
+
+				create_embed("Campaign", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully started the campaign. Backers can pledge with /campaign pledge.\n\n", format_campaign(campaign)), []*discordgo.MessageEmbedField{})
+			case "pledge":
+				campaign, ok := data.Campaigns[subcommand.Options[0].StringValue()]
+				if !ok {
+					create_embed("Campaign", data_handler.session, data_handler.interaction, "**ERROR:** That campaign does not exist or has ended", []*discordgo.MessageEmbedField{})
+					return
+				}
+				amount := cheesecoin_option(subcommand.Options[1])
+
+				cheese_account := data.PersonalAccounts[data.Users[data_handler.user.ID].PersonalAccount]
+				if amount > cheese_account.Balance {
+					create_embed("Campaign", data_handler.session, data_handler.interaction, "**ERROR:** You do not have enough funds.", []*discordgo.MessageEmbedField{})
+					return
+				}
+				cheese_account.Balance -= amount
+				data.Escrow.Balance += amount
+				campaign.Pledges[data_handler.user.ID] += amount
+
+				create_embed("Campaign", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully pledged ", format_cheesecoins(amount), ", for a total of ", format_cheesecoins(campaign.Pledges[data_handler.user.ID]),
+					". It is held until the campaign ends, then paid if the goal is reached or refunded if not.\n\n", format_campaign(campaign)), []*discordgo.MessageEmbedField{})
+				check_campaign_milestone(data_handler.session, campaign)
+			case "cancel":
+				id := subcommand.Options[0].StringValue()
+				campaign, ok := data.Campaigns[id]
+				if !ok {
+					create_embed("Campaign", data_handler.session, data_handler.interaction, "**ERROR:** That campaign does not exist or has ended", []*discordgo.MessageEmbedField{})
+					return
+				}
+				if !user_has_org(data_handler.user, campaign.Organisation, RoleOwner) {
+					create_embed("Campaign", data_handler.session, data_handler.interaction, "**ERROR:** You do not own that organisation", []*discordgo.MessageEmbedField{})
+					return
+				}
+
+				delete(data.Campaigns, id)
+				refund_campaign(data_handler.session, campaign, fmt.Sprint("The campaign was cancelled by ", format_user(data_handler.user.ID), ", so every pledge has been refunded."))
+				create_embed("Campaign", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully cancelled the campaign and refunded ", format_cheesecoins(campaign_total(campaign)), " to ", len(campaign.Pledges), " backer(s)."), []*discordgo.MessageEmbedField{})
+			case "list":
+				description := ""
+				for _, campaign := range data.Campaigns {
+					description += "\n\n" + format_campaign(campaign)
+					if pledged, ok := campaign.Pledges[data_handler.user.ID]; ok {
+						description += fmt.Sprint(" You have pledged ", format_cheesecoins(pledged), ".")
+					}
+				}
+				if description == "" {
+					description = "There are no campaigns at the moment."
+				}
+
+				create_embed("Campaign", data_handler.session, data_handler.interaction, strings.TrimSpace(description), []*discordgo.MessageEmbedField{})
+			}
+		},
+		"gambling_limit": func(data_handler HandlerData) {
+			usr := data.Users[data_handler.user.ID]
+			apply_pending_loss_limit(usr)
+
+			description := ""
+			if len(data_handler.interaction_data.Options) > 0 {
+				limit := cheesecoin_option(data_handler.interaction_data.Options[0])
+
+				// Tighter limits apply straight away but looser limits have to wait
+				if usr.LossLimit == 0 || (limit > 0 && limit <= usr.LossLimit) {
+					usr.LossLimit = limit
+					usr.PendingLossLimitTime = time.Time{}
+					description = "Sucessfully changed your daily loss limit.\n"
+				} else {
+					usr.PendingLossLimit = limit
+					usr.PendingLossLimitTime = time.Now().Add(LossLimitDelay)
+					description = fmt.Sprint("Your daily loss limit will change <t:", usr.PendingLossLimitTime.Unix(), ":R>. Raising or removing a limit always takes 24 hours.\n")
+				}
+			}
+
+			description += fmt.Sprintf("```\n%-20s %s\n%-20s %s\n", "Daily loss limit:", format_limit(usr.LossLimit), "Lost today:", format_cheesecoins(gambling_loss_today(usr)))
+			if !usr.PendingLossLimitTime.IsZero() {
+				description += fmt.Sprintf("%-20s %s\n", "Pending limit:", format_limit(usr.PendingLossLimit))
+			}
+			if data.BetInterval > 0 {
+				description += fmt.Sprintf("%-20s %ds\n", "Time between bets:", data.BetInterval)
+			}
+			description += "```"
+			if time.Now().Before(usr.SelfExcluded) {
+				description += fmt.Sprint("\nYou are excluded from the casino until <t:", usr.SelfExcluded.Unix(), ":f>.")
+			}
+
+			create_embed("Gambling Limit", data_handler.session, data_handler.interaction, description, []*discordgo.MessageEmbedField{})
+		},
+		"self_exclude": func(data_handler HandlerData) {
+			usr := data.Users[data_handler.user.ID]
+			until := time.Now().AddDate(0, 0, int(data_handler.interaction_data.Options[0].IntValue()))
+
+			// An exclusion can be extended but never shortened
+			if until.After(usr.SelfExcluded) {
+				usr.SelfExcluded = until
+			}
+
+			create_embed("Self Exclusion", data_handler.session, data_handler.interaction, fmt.Sprint("You are excluded from all casino games until <t:", usr.SelfExcluded.Unix(),
+				":f>. This cannot be undone. Any blackjack game you are playing can still be finished."), []*discordgo.MessageEmbedField{})
+		},
+		"sudo_gambling_policy": func(data_handler HandlerData) {
+			if !data.Users[data_handler.user.ID].SuperUser {
+				create_embed("Gambling Policy", data_handler.session, data_handler.interaction, "**ERROR:** You are not a super user", []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			data.BetInterval = int(data_handler.interaction_data.Options[0].IntValue())
+			data.BlockOverdueGambling = data_handler.interaction_data.Options[1].BoolValue()
+
+			overdue := "can"
+			if data.BlockOverdueGambling {
+				overdue = "cannot"
+			}
+			create_embed("Gambling Policy", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully set the gambling policy. Bets must be at least ", data.BetInterval,
+				" second(s) apart and people with overdue loans ", overdue, " gamble."), []*discordgo.MessageEmbedField{})
+		},
+		"casino_report": func(data_handler HandlerData) {
+			if !user_has_org(data_handler.user, casino, RoleViewer) {
+				create_embed("Casino Report", data_handler.session, data_handler.interaction, "**ERROR:** You are not a member of the casino.", []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			days := 7
+			if len(data_handler.interaction_data.Options) > 0 {
+				days = int(data_handler.interaction_data.Options[0].IntValue())
+			}
+
+			create_embed("Casino Report", data_handler.session, data_handler.interaction, fmt.Sprint("**The last ", days, " days**\n", casino_report(time.Now().AddDate(0, 0, -days))), []*discordgo.MessageEmbedField{})
+		},
+		"casino_digest": func(data_handler HandlerData) {
+			if !user_has_org(data_handler.user, casino, RoleOwner) {
+				create_embed("Casino Digest", data_handler.session, data_handler.interaction, "**ERROR:** You are not the casino owner.", []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			data.Users[data_handler.user.ID].CasinoDigest = data_handler.interaction_data.Options[0].BoolValue()
+
+			if data.Users[data_handler.user.ID].CasinoDigest {
+				create_embed("Casino Digest", data_handler.session, data_handler.interaction, "You will now get a casino report each day instead of a message for each win.", []*discordgo.MessageEmbedField{})
+			} else {
+				create_embed("Casino Digest", data_handler.session, data_handler.interaction, "You will now get a message for each win at the casino.", []*discordgo.MessageEmbedField{})
+			}
+		},
+		"gambling_set_returns": func(data_handler HandlerData) {
+			if !user_has_org(data_handler.user, casino, RoleOwner) {
+				create_embed("Gambling Set Returns", data_handler.session, data_handler.interaction, "**ERROR:** You are not the casino owner.", []*discordgo.MessageEmbedField{})
+				return
+			}
+
+			data.CasinoReturns = data_handler.interaction_data.Options[0].Value.(float64)
+
+			create_embed("Gambling Set Returns", data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully set gambling returns to ", data.CasinoReturns, "."), []*discordgo.MessageEmbedField{})
+		},
+	}
+	componentHandlers = map[string]func(data_handler HandlerData, id string){
+		"blackjack_hit": func(data_handler HandlerData, id string) {
+			game, ok := data.BlackjackGames[id]
+			if !ok {
+				update_embed("Blackjack", data_handler.session, data_handler.interaction, "This game has already finished.")
+				return
+			}
+			if game.User != data_handler.user.ID {
+				ephemeral_embed("Blackjack", data_handler.session, data_handler.interaction, "**ERROR:** This is not your game.")
+				return
+			}
+
+			game.Player = append(game.Player, draw_card(game))
+			game.Expires = time.Now().Add(BlackjackTimeout)
+
+			if value, _ := hand_value(game.Player); value >= 21 {
+				update_embed("Blackjack", data_handler.session, data_handler.interaction, settle_blackjack(data_handler.session, id))
+				return
+			}
+
+			update_buttons("Blackjack", data_handler.session, data_handler.interaction, fmt.Sprint(format_blackjack(game, true), "\n\nThe game expires <t:", game.Expires.Unix(), ":R>."), blackjack_buttons(id))
+		},
+		"blackjack_stand": func(data_handler HandlerData, id string) {
+			game, ok := data.BlackjackGames[id]
+			if !ok {
+				update_embed("Blackjack", data_handler.session, data_handler.interaction, "This game has already finished.")
+				return
+			}
+			if game.User != data_handler.user.ID {
+				ephemeral_embed("Blackjack", data_handler.session, data_handler.interaction, "**ERROR:** This is not your game.")
+				return
+			}
+
+			update_embed("Blackjack", data_handler.session, data_handler.interaction, settle_blackjack(data_handler.session, id))
+		},
+		"blackjack_double": func(data_handler HandlerData, id string) {
+			game, ok := data.BlackjackGames[id]
+			if !ok {
+				update_embed("Blackjack", data_handler.session, data_handler.interaction, "This game has already finished.")
+				return
+			}
+			if game.User != data_handler.user.ID {
+				ephemeral_embed("Blackjack", data_handler.session, data_handler.interaction, "**ERROR:** This is not your game.")
+				return
+			}
+			if len(game.Player) != 2 {
+				update_buttons("Blackjack", data_handler.session, data_handler.interaction, fmt.Sprint(format_blackjack(game, true), "\n\n**ERROR:** You can only double on your first two cards."), blackjack_buttons(id))
+				return
+			}
+
+			// Double the stake, draw exactly one more card and stand. Doubling raises the bet, so it must pass the same checks as the first one
+			// apart from the bet interval, and the casino must cover a win on the doubled stake if the cover reserved at the start does not already.
+			if err := check_gambling_allowed(game.User, game.Stake*2); err != "" {
+				update_buttons("Blackjack", data_handler.session, data_handler.interaction, fmt.Sprint(format_blackjack(game, true), "\n\n", err), blackjack_buttons(id))
+				return
+			}
+			extra_cover := game.Stake*2 - game.Cover
+			if extra_cover < 0 {
+				extra_cover = 0
+			}
+			if err := reserve_bet(game.User, game.Stake, extra_cover); err != "" {
+				update_buttons("Blackjack", data_handler.session, data_handler.interaction, fmt.Sprint(format_blackjack(game, true), "\n\n", err), blackjack_buttons(id))
+				return
+			}
+			game.Cover += extra_cover
+			game.Stake *= 2
+			game.Player = append(game.Player, draw_card(game))
+
+			update_embed("Blackjack", data_handler.session, data_handler.interaction, settle_blackjack(data_handler.session, id))
+		},
+		"wager_accept": func(data_handler HandlerData, id string) {
+			wager, ok := data.Wagers[id]
+			if !ok || wager.Accepted || wager.Counterparty != data_handler.user.ID {
+				update_embed("Wager offer", data_handler.session, data_handler.interaction, "This offer has already been dealt with or has expired.")
+				return
+			}
+
+			cheese_account := data.PersonalAccounts[data.Users[data_handler.user.ID].PersonalAccount]
+			if wager.Amount > cheese_account.Balance {
+				update_buttons("Wager offer", data_handler.session, data_handler.interaction, fmt.Sprint(format_user(wager.Proposer), " would like to bet you ", format_cheesecoins(wager.Amount), " each on:\n> ", wager.Terms,
+					"\n\n**ERROR:** You do not have enough funds."), []discordgo.MessageComponent{
+					discordgo.Button{Label: "Accept", Style: discordgo.SuccessButton, CustomID: "wager_accept:" + id},
+					discordgo.Button{Label: "Decline", Style: discordgo.DangerButton, CustomID: "wager_decline:" + id},
+				})
+				return
+			}
+			cheese_account.Balance -= wager.Amount
+			data.Escrow.Balance += wager.Amount
+			wager.Accepted = true
+			wager.Deadline = time.Now().Add(time.Hour * 24 * time.Duration(wager.Days))
+
+			description := fmt.Sprint(format_wager(wager), "\n\nOnce it is decided, pick the winner below. The wager is settled when you both agree, and the stakes are refunded if there is no winner <t:", wager.Deadline.Unix(), ":R>.")
+			send_buttons("Wager accepted", data_handler.session, wager.Proposer, description, wager_winner_buttons("wager_vote", id))
+			if wager.Arbiter != "" {
+				send_embed("Wager arbiter", data_handler.session, wager.Arbiter, fmt.Sprint(format_wager(wager), "\n\nYou will be asked to pick the winner if they disagree."), []*discordgo.MessageEmbedField{})
+			}
+			update_buttons("Wager accepted", data_handler.session, data_handler.interaction, description, wager_winner_buttons("wager_vote", id))
+		},
+		"wager_decline": func(data_handler HandlerData, id string) {
+			wager, ok := data.Wagers[id]
+			if !ok || wager.Accepted || wager.Counterparty != data_handler.user.ID {
+				update_embed("Wager offer", data_handler.session, data_handler.interaction, "This offer has already been dealt with or has expired.")
+				return
+			}
+
+			refund_wager(data_handler.session, id, fmt.Sprint(format_user(wager.Counterparty), " has declined the wager."))
+			update_embed("Wager offer", data_handler.session, data_handler.interaction, fmt.Sprint("You have declined the wager from ", format_user(wager.Proposer), "."))
+		},
+		"wager_vote": func(data_handler HandlerData, id string) {
+			id, winner := split_wager_winner(id)
+			wager, ok := data.Wagers[id]
+			if !ok || !wager.Accepted || (wager.Proposer != data_handler.user.ID && wager.Counterparty != data_handler.user.ID) || (winner != wager.Proposer && winner != wager.Counterparty) {
+				update_embed("Wager", data_handler.session, data_handler.interaction, "This wager has already been settled or refunded.")
+				return
+			}
+
+			result := vote_wager(data_handler.session, id, data_handler.user.ID, winner)
+			if _, ok := data.Wagers[id]; !ok {
+				update_embed("Wager", data_handler.session, data_handler.interaction, result)
+				return
+			}
+			update_buttons("Wager", data_handler.session, data_handler.interaction, fmt.Sprint(format_wager(wager), "\n\n", result, " You can change your answer below."), wager_winner_buttons("wager_vote", id))
+		},
+		"wager_arbitrate": func(data_handler HandlerData, id string) {
+			id, winner := split_wager_winner(id)
+			wager, ok := data.Wagers[id]
+			if !ok || !wager.Disputed || wager.Arbiter != data_handler.user.ID || (winner != wager.Proposer && winner != wager.Counterparty) {
+				update_embed("Wager dispute", data_handler.session, data_handler.interaction, "This wager has already been settled or refunded.")
+				return
+			}
+
+			settle_wager(data_handler.session, id, winner)
+			update_embed("Wager dispute", data_handler.session, data_handler.interaction, fmt.Sprint("You have decided that ", format_user(winner), " won the wager."))
+		},
+		"escrow_arbitrate_release": func(data_handler HandlerData, id string) {
+			escrow, ok := data.EscrowPayments[id]
+			if !ok || !escrow.Disputed {
+				update_embed("Escrow dispute", data_handler.session, data_handler.interaction, "This dispute has already been decided.")
+				return
+			}
+			if !data.Users[data_handler.user.ID].SuperUser {
+				update_embed("Escrow dispute", data_handler.session, data_handler.interaction, "**ERROR:** You are not a super user")
+				return
+			}
+
+			update_embed("Escrow dispute", data_handler.session, data_handler.interaction, release_escrow(data_handler.session, id, fmt.Sprint("The dispute was decided by ", format_user(data_handler.user.ID), ".")))
+		},
+		"escrow_arbitrate_refund": func(data_handler HandlerData, id string) {
+			escrow, ok := data.EscrowPayments[id]
+			if !ok || !escrow.Disputed {
+				update_embed("Escrow dispute", data_handler.session, data_handler.interaction, "This dispute has already been decided.")
+				return
+			}
+			if !data.Users[data_handler.user.ID].SuperUser {
+				update_embed("Escrow dispute", data_handler.session, data_handler.interaction, "**ERROR:** You are not a super user")
+				return
+			}
+
+			update_embed("Escrow dispute", data_handler.session, data_handler.interaction, refund_escrow(data_handler.session, id, fmt.Sprint("The dispute was decided by ", format_user(data_handler.user.ID), ".")))
+		},
+		"delete_org_confirm": func(data_handler HandlerData, id string) {
+			organisation_account, ok := data.OrganisationAccounts[id]
+			if !ok {
+				update_embed("Delete organisation", data_handler.session, data_handler.interaction, "**ERROR:** That organisation no longer exists")
+				return
+			}
+			if !user_has_org(data_handler.user, id, RoleOwner) {
+				ephemeral_embed("Delete organisation", data_handler.session, data_handler.interaction, "**ERROR:** You do not own that organisation")
+				return
+			}
+
+			// Things may have changed since the deletion was requested
+			if err := check_org_deletion(id, data_handler.user.ID); err != "" {
+				update_embed("Delete organisation", data_handler.session, data_handler.interaction, err)
+				return
+			}
+
+			user_account := data.PersonalAccounts[data.Users[data_handler.user.ID].PersonalAccount]
+			sucsess, err, tax, _ := transaction(organisation_account.Balance, organisation_account, user_account, "destroyed organisation", data_handler.session, nil)
+			if !sucsess {
+				update_embed("Delete organisation", data_handler.session, data_handler.interaction, err)
+				return
+			}
+
+			archive_org(id)
+
+			update_embed("Delete organisation", data_handler.session, data_handler.interaction, fmt.Sprint(
+				"Sucessfully deleted ", organisation_account.Name, " all funds have been transfered to your personal account (with ", format_cheesecoins(tax), " in tax)"))
+		},
+		"delete_org_cancel": func(data_handler HandlerData, id string) {
+			if _, ok := data.OrganisationAccounts[id]; ok && !user_has_org(data_handler.user, id, RoleOwner) {
+				ephemeral_embed("Delete organisation", data_handler.session, data_handler.interaction, "**ERROR:** You do not own that organisation")
+				return
+			}
+			update_embed("Delete organisation", data_handler.session, data_handler.interaction, "The organisation has not been deleted.")
+		},
+		"transfer_org_confirm": func(data_handler HandlerData, id string) {
+			offer, ok := data.TransferOffers[id]
+			if !ok || offer.Confirmed {
+				update_embed("Confirm organisation transfer", data_handler.session, data_handler.interaction, "This offer has already been dealt with or has expired.")
+				return
+			}
+			if !data.Users[data_handler.user.ID].SuperUser {
+				update_embed("Confirm organisation transfer", data_handler.session, data_handler.interaction, "**ERROR:** You are not a super user")
+				return
+			}
+
+			offer.Confirmed = true
+			send_transfer_offer(data_handler.session, id)
+
+			update_embed("Confirm organisation transfer", data_handler.session, data_handler.interaction, fmt.Sprint("You have confirmed the transfer of ", data.OrganisationAccounts[offer.Organisation].Name, " to ", format_user(offer.To), "."))
+		},
+		"transfer_org_reject": func(data_handler HandlerData, id string) {
+			offer, ok := data.TransferOffers[id]
+			if !ok || offer.Confirmed {
+				update_embed("Confirm organisation transfer", data_handler.session, data_handler.interaction, "This offer has already been dealt with or has expired.")
+				return
+			}
+			if !data.Users[data_handler.user.ID].SuperUser {
+				update_embed("Confirm organisation transfer", data_handler.session, data_handler.interaction, "**ERROR:** You are not a super user")
+				return
+			}
+
+			delete(data.TransferOffers, id)
+			organisation_name := data.OrganisationAccounts[offer.Organisation].Name
+			send_embed("Transfer organisation", data_handler.session, offer.From, fmt.Sprint("A super user has rejected the transfer of ", organisation_name, " to ", format_user(offer.To), "."), []*discordgo.MessageEmbedField{})
+
+			update_embed("Confirm organisation transfer", data_handler.session, data_handler.interaction, fmt.Sprint("You have rejected the transfer of ", organisation_name, " to ", format_user(offer.To), "."))
+		},
+		"transfer_org_accept": func(data_handler HandlerData, id string) {
+			offer, ok := data.TransferOffers[id]
+			if !ok || offer.To != data_handler.user.ID {
+				update_embed("Organisation transfer offer", data_handler.session, data_handler.interaction, "This offer has already been dealt with or has expired.")
+				return
+			}
+			delete(data.TransferOffers, id)
+
+			// The offer may not have been confirmed by a super user yet, or may have expired before it was removed
+			if !offer.Confirmed || time.Now().After(offer.Expires) {
+				update_embed("Organisation transfer offer", data_handler.session, data_handler.interaction, "**ERROR:** This offer has not been confirmed or has expired, so it has been cancelled.")
+				return
+			}
+
+			// After a shareholder vote every owner except the new one becomes a viewer
+			if offer.ShareholderVote {
+				account, ok := data.OrganisationAccounts[offer.Organisation]
+				if !ok {
+					update_embed("Organisation transfer offer", data_handler.session, data_handler.interaction, "**ERROR:** That organisation no longer exists.")
+					return
+				}
+
+				record := fmt.Sprint(account.Name, " was transfered to ", format_user(offer.To), " by a vote of its shareholders on <t:", time.Now().Unix(), ":f>.")
+				for _, owner := range org_owners(account) {
+					if owner != offer.To {
+						account.Members[owner] = RoleViewer
+						send_embed("Organisation transfered", data_handler.session, owner, record+" You are now a viewer.", []*discordgo.MessageEmbedField{})
+					}
+				}
+				add_org_member(offer.To, offer.Organisation, RoleOwner)
+				update_embed("Organisation transfered", data_handler.session, data_handler.interaction, record)
+				return
+			}
+
+			// The organisation may have been deleted or given away since the offer was made
+			if account, ok := data.OrganisationAccounts[offer.Organisation]; !ok || account.Members[offer.From] != RoleOwner {
+				update_embed("Organisation transfer offer", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** ", format_user(offer.From), " no longer owns that organisation."))
+				return
+			}
+
+			remove_org_member(offer.From, offer.Organisation)
+			add_org_member(offer.To, offer.Organisation, RoleOwner)
+
+			record := fmt.Sprint(data.OrganisationAccounts[offer.Organisation].Name, " was transfered from ", format_user(offer.From), " to ", format_user(offer.To), " on <t:", time.Now().Unix(), ":f>.")
+			send_embed("Organisation transfered", data_handler.session, offer.From, record, []*discordgo.MessageEmbedField{})
+			update_embed("Organisation transfered", data_handler.session, data_handler.interaction, record)
+		},
+		"transfer_org_decline": func(data_handler HandlerData, id string) {
+			offer, ok := data.TransferOffers[id]
+			if !ok || offer.To != data_handler.user.ID {
+				update_embed("Organisation transfer offer", data_handler.session, data_handler.interaction, "This offer has already been dealt with or has expired.")
+				return
+			}
+			delete(data.TransferOffers, id)
+
+			organisation_name := data.OrganisationAccounts[offer.Organisation].Name
+			if !offer.ShareholderVote {
+				send_embed("Transfer organisation", data_handler.session, offer.From, fmt.Sprint(format_user(offer.To), " has declined to take ownership of ", organisation_name, "."), []*discordgo.MessageEmbedField{})
+			}
+			update_embed("Organisation transfer offer", data_handler.session, data_handler.interaction, fmt.Sprint("You have declined to take ownership of ", organisation_name, "."))
+		},
+		"payment_approve": func(data_handler HandlerData, id string) {
+			payment, ok := data.PendingPayments[id]
+			if !ok || time.Now().After(payment.Expires) {
+				update_embed("Approve payment", data_handler.session, data_handler.interaction, "This payment has already been dealt with or has expired.")
+				return
+			}
+			if payment.Requester == data_handler.user.ID || !user_has_org(data_handler.user, payment.Organisation, RoleTreasurer) {
+				update_embed("Approve payment", data_handler.session, data_handler.interaction, "**ERROR:** You cannot approve this payment")
+				return
+			}
+			delete(data.PendingPayments, id)
+
+			// The requester may have been removed or demoted while waiting for approval, which also removes their limits
+			payer_account := data.OrganisationAccounts[payment.Organisation]
+			if !user_has_org(&discordgo.User{ID: payment.Requester}, payment.Organisation, RoleTreasurer) {
+				update_embed("Approve payment", data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** ", format_user(payment.Requester), " is no longer a treasurer of ", payer_account.Name, " so the payment has been cancelled"))
+				return
+			}
+			recipiant_account, ok := get_account(payment.Recipiant)
+			if !ok {
+				update_embed("Approve payment", data_handler.session, data_handler.interaction, "**ERROR:** The recipiant no longer exists")
+				return
+			}
+
+			// The requester's limits may have changed or been used up while waiting for approval
+			if err := check_spending_limit(payer_account, payment.Requester, payment.Amount); err != "" {
+				update_embed("Approve payment", data_handler.session, data_handler.interaction, err)
+				return
+			}
+
+			sucsess, err, tax, sales_tax := sales_transaction(payment.Amount, payer_account, recipiant_account, payer_account.Name, data_handler.session, nil)
+			if !sucsess {
+				send_embed("Payment", data_handler.session, payment.Requester, fmt.Sprint("Your payment of ", format_cheesecoins(payment.Amount), " to ", recipiant_account.Name, " was approved but failed.\n", err), []*discordgo.MessageEmbedField{})
+				update_embed("Approve payment", data_handler.session, data_handler.interaction, err)
+				return
+			}
+			record_spending(payer_account, payment.Requester, payment.Amount)
+
+			send_embed("Payment", data_handler.session, payment.Requester, fmt.Sprint(format_user(data_handler.user.ID), " approved your payment of ", format_cheesecoins(payment.Amount), " from ", payer_account.Name, " to ", recipiant_account.Name,
+				".", format_receipt(payment.Amount, tax, sales_tax), err), []*discordgo.MessageEmbedField{})
+			update_embed("Approve payment", data_handler.session, data_handler.interaction, fmt.Sprint("You have approved the payment of ", format_cheesecoins(payment.Amount), " from ", payer_account.Name, " to ", recipiant_account.Name, "."))
+		},
+		"payment_reject": func(data_handler HandlerData, id string) {
+			payment, ok := data.PendingPayments[id]
+			if !ok || time.Now().After(payment.Expires) {
+				update_embed("Approve payment", data_handler.session, data_handler.interaction, "This payment has already been dealt with or has expired.")
+				return
+			}
+			if payment.Requester == data_handler.user.ID || !user_has_org(data_handler.user, payment.Organisation, RoleTreasurer) {
+				update_embed("Approve payment", data_handler.session, data_handler.interaction, "**ERROR:** You cannot reject this payment")
+				return
+			}
+			delete(data.PendingPayments, id)
+
+			send_embed("Payment", data_handler.session, payment.Requester, fmt.Sprint(format_user(data_handler.user.ID), " rejected your payment of ", format_cheesecoins(payment.Amount), " from ", data.OrganisationAccounts[payment.Organisation].Name, "."), []*discordgo.MessageEmbedField{})
+			update_embed("Approve payment", data_handler.session, data_handler.interaction, fmt.Sprint("You have rejected the payment of ", format_cheesecoins(payment.Amount), " from ", data.OrganisationAccounts[payment.Organisation].Name, "."))
+		},
+	}
+	commandAutocomplete = map[string][]int8{
+		"help":                     {},
+		"balances":                 {},
 		"pay":                      {AutoCompleteAllAccounts, AutoCompleteNone, AutoCompleteOwnedOrgs},
 		"transfer_org":             {AutoCompleteOwnedOrgs, AutoCompleteNonSelfUsers},
 		"create_org":               {AutoCompleteNone},
+		"org_add_member":           {AutoCompleteOwnedOrgs, AutoCompleteNonSelfUsers, AutoCompleteNone},
+		"org_remove_member":        {AutoCompleteOwnedOrgs, AutoCompleteUsers},
+		"org_members":              {AutoCompleteOwnedOrgs},
+		"org_set_limit":            {AutoCompleteOwnedOrgs, AutoCompleteUsers, AutoCompleteNone, AutoCompleteNone},
+		"org_set_approval":         {AutoCompleteOwnedOrgs, AutoCompleteNone},
+		"payroll add":              {AutoCompleteOwnedOrgs, AutoCompleteUsers, AutoCompleteNone, AutoCompleteNone},
+		"payroll remove":           {AutoCompleteOwnedOrgs, AutoCompleteUsers},
+		"payroll list":             {AutoCompleteOwnedOrgs},
+		"payroll run":              {AutoCompleteOwnedOrgs},
+		"shares issue":             {AutoCompleteOwnedOrgs, AutoCompleteNone, AutoCompleteUsers},
+		"shares transfer":          {AutoCompleteHeldShares, AutoCompleteNonSelfUsers, AutoCompleteNone},
+		"shares holdings":          {},
+		"shares vote":              {AutoCompleteHeldShares, AutoCompleteUsers},
+		"dividend":                 {AutoCompleteOwnedOrgs, AutoCompleteNone},
+		"order buy":                {AutoCompleteAllOrgs, AutoCompleteNone, AutoCompleteNone},
+		"order sell":               {AutoCompleteHeldShares, AutoCompleteNone, AutoCompleteNone},
+		"order cancel":             {AutoCompleteNone},
+		"order list":               {},
+		"market":                   {AutoCompleteAllOrgs},
+		"org_profile":              {AutoCompleteOwnedOrgs, AutoCompleteNone, AutoCompleteNone, AutoCompleteNone, AutoCompleteNone},
+		"org_info":                 {AutoCompleteAllOrgs},
+		"directory":                {AutoCompleteNone, AutoCompleteNone},
 		"rename_org":               {AutoCompleteOwnedOrgs, AutoCompleteNone},
 		"answer_mp_rollcall":       {},
 		"delete_org":               {AutoCompleteOwnedOrgs},
-		"sudo_set_wealth_tax":      {AutoCompleteNone},
-		"sudo_set_transaction_tax": {AutoCompleteNone},
+		"economy":                  {},
+		"chart balance":            {AutoCompleteOwnedOrgs, AutoCompleteNone},
+		"chart economy":            {AutoCompleteNone, AutoCompleteNone},
+		"sudo_set_wealth_tax":      {AutoCompleteNone, AutoCompleteNone},
+		"sudo_set_transaction_tax": {AutoCompleteNone, AutoCompleteNone},
+		"sudo_set_sales_tax":       {AutoCompleteNone, AutoCompleteNone},
+		"sudo_set_lottery_tax":     {AutoCompleteNone, AutoCompleteNone},
+		"sudo_set_winnings_tax":    {AutoCompleteNone, AutoCompleteNone},
 		"sudo_set_bank_holiday":    {AutoCompleteNone, AutoCompleteNone, AutoCompleteNone},
 		"bank_holidays":            {},
+		"sudo_set_ubi":             {AutoCompleteNone, AutoCompleteNone, AutoCompleteNone},
 		"sudo_loan":                {AutoCompleteAllAccounts, AutoCompleteNone},
-		"sudo_set_interest_rate":   {AutoCompleteNone},
+		"sudo_set_org_fee":         {AutoCompleteNone},
+		"sudo_set_interest_rate":   {AutoCompleteNone, AutoCompleteNone},
+		"rates_history":            {},
 		"view_bank_loans":          {},
 		"gamble":                   {AutoCompleteNone, AutoCompleteNone},
+		"casino_seed":              {AutoCompleteNone},
+		"blackjack":                {AutoCompleteNone},
+		"blackjack_rules":          {AutoCompleteNone, AutoCompleteNone, AutoCompleteNone},
+		"casino_verify":            {AutoCompleteNone, AutoCompleteNone, AutoCompleteNone, AutoCompleteNone},
 		"gambling_set_returns":     {AutoCompleteNone},
+		"casino_report":            {AutoCompleteNone},
+		"casino_digest":            {AutoCompleteNone},
+		"gambling_limit":           {AutoCompleteNone},
+		"self_exclude":             {AutoCompleteNone},
+		"sudo_gambling_policy":     {AutoCompleteNone, AutoCompleteNone},
+		"roulette":                 {AutoCompleteNone},
+		"roulette_set_edge":        {AutoCompleteNone},
+		"lottery start":            {AutoCompleteNone, AutoCompleteNone, AutoCompleteNone, AutoCompleteNone},
+		"lottery buy":              {AutoCompleteNone},
+		"lottery status":           {},
+		"wager propose":            {AutoCompleteNonSelfUsers, AutoCompleteNone, AutoCompleteNone, AutoCompleteNonSelfUsers, AutoCompleteNone},
+		"wager list":               {},
+		"escrow create":            {AutoCompleteAllAccounts, AutoCompleteNone, AutoCompleteNone, AutoCompleteNone, AutoCompleteOwnedOrgs},
+		"escrow release":           {AutoCompleteEscrows},
+		"escrow refund":            {AutoCompleteEscrows},
+		"escrow dispute":           {AutoCompleteEscrows, AutoCompleteNone},
+		"escrow list":              {},
+		"campaign create":          {AutoCompleteOwnedOrgs, AutoCompleteNone, AutoCompleteNone, AutoCompleteNone},
+		"campaign pledge":          {AutoCompleteCampaigns, AutoCompleteNone},
+		"campaign cancel":          {AutoCompleteCampaigns},
+		"campaign list":            {},
+	}
+	role_names = []string{"Viewer", "Treasurer", "Owner"}
+
+	org_categories = []string{"Business", "Government", "Finance", "Charity", "Political Party", "Media", "Club", "Other"}
+
+	economy_metrics = []EconomyMetric{
+		{Id: "money_supply", Name: "Money Supply", Value: func(s *EconomySnapshot) float64 { return float64(s.MoneySupply) / 100 }},
+		{Id: "treasury", Name: "Treasury", Value: func(s *EconomySnapshot) float64 { return float64(s.Treasury) / 100 }},
+		{Id: "tax", Name: "Tax Today", Value: func(s *EconomySnapshot) float64 { return float64(s.TaxCollected) / 100 }},
+		{Id: "volume", Name: "Volume Today", Value: func(s *EconomySnapshot) float64 { return float64(s.TransactionVolume) / 100 }},
+		{Id: "transactions", Name: "Transactions Today", Value: func(s *EconomySnapshot) float64 { return float64(s.Transactions) }},
+		{Id: "velocity", Name: "Velocity", Value: func(s *EconomySnapshot) float64 {
+			// The velocity of money over a day
+			if s.MoneySupply == 0 {
+				return 0
+			}
+			return float64(s.TransactionVolume) / float64(s.MoneySupply)
+		}},
+		{Id: "gini", Name: "Gini Coefficient", Value: func(s *EconomySnapshot) float64 { return s.Gini }},
+		{Id: "active", Name: "Active Accounts", Value: func(s *EconomySnapshot) float64 { return float64(s.ActiveAccounts) }},
+		{Id: "loans", Name: "Loans Outstanding", Value: func(s *EconomySnapshot) float64 { return float64(s.OutstandingLoans) / 100 }},
+	}
+	commandLimits = map[string]map[string]OptionLimit{
+		"pay":                      {"cheesecoin": {Min: 0.01, Max: MaxCheesecoinInput, Cheesecoin: true}},
+		"org_set_limit":            {"daily": {Min: 0, Max: MaxCheesecoinInput, Cheesecoin: true}, "per_payment": {Min: 0, Max: MaxCheesecoinInput, Cheesecoin: true}},
+		"org_set_approval":         {"threshold": {Min: 0, Max: MaxCheesecoinInput, Cheesecoin: true}},
+		"payroll add":              {"salary": {Min: 0.01, Max: MaxCheesecoinInput, Cheesecoin: true}, "interval_days": {Min: 1, Max: 365}},
+		"shares issue":             {"shares": {Min: 1, Max: MaxCheesecoinInput}},
+		"shares transfer":          {"shares": {Min: 1, Max: MaxCheesecoinInput}},
+		"dividend":                 {"cheesecoin": {Min: 0.01, Max: MaxCheesecoinInput, Cheesecoin: true}},
+		"order buy":                {"shares": {Min: 1, Max: MaxCheesecoinInput}, "price": {Min: 0.01, Max: MaxCheesecoinInput, Cheesecoin: true}},
+		"order sell":               {"shares": {Min: 1, Max: MaxCheesecoinInput}, "price": {Min: 0.01, Max: MaxCheesecoinInput, Cheesecoin: true}},
+		"sudo_set_wealth_tax":      {"new_tax": {Min: 0, Max: 100}},
+		"sudo_set_transaction_tax": {"new_tax": {Min: 0, Max: 100}},
+		"sudo_set_sales_tax":       {"new_tax": {Min: 0, Max: 100}},
+		"sudo_set_lottery_tax":     {"new_tax": {Min: 0, Max: 50}},
+		"sudo_set_winnings_tax":    {"new_tax": {Min: 0, Max: 100}},
+		"sudo_set_bank_holiday":    {"day": {Min: 1, Max: 31}},
+		"sudo_set_ubi":             {"cheesecoin": {Min: 0, Max: MaxCheesecoinInput, Cheesecoin: true}, "interval_days": {Min: 1, Max: 365}, "active_days": {Min: 1, Max: 365}},
+		"sudo_set_org_fee":         {"cheesecoin": {Min: 0, Max: MaxCheesecoinInput, Cheesecoin: true}},
+		"sudo_loan":                {"amount": {Min: 0.01, Max: MaxCheesecoinInput, Cheesecoin: true}},
+		"sudo_set_interest_rate":   {"new_interest": {Min: 0, Max: 100}},
+		"gamble":                   {"cheesecoin": {Min: 0.01, Max: MaxCheesecoinInput, Cheesecoin: true}, "number": {Min: 1, Max: 6}},
+		"casino_verify":            {"nonce": {Min: 0, Max: math.MaxInt32}, "sides": {Min: 2, Max: math.MaxInt32}},
+		"blackjack":                {"stake": {Min: 0.01, Max: MaxCheesecoinInput, Cheesecoin: true}},
+		"blackjack_rules":          {"decks": {Min: 1, Max: 8}, "blackjack_pays": {Min: 1, Max: 3}},
+		"gambling_set_returns":     {"returns": {Min: 1, Max: 100}},
+		"gambling_limit":           {"daily_loss": {Min: 0, Max: MaxCheesecoinInput, Cheesecoin: true}},
+		"sudo_gambling_policy":     {"min_bet_interval": {Min: 0, Max: 3600}},
+		"roulette_set_edge":        {"edge": {Min: 0, Max: 50}},
+		"lottery start":            {"ticket_price": {Min: 0.01, Max: MaxCheesecoinInput, Cheesecoin: true}, "hours": {Min: 1, Max: 24 * 30}, "winners": {Min: 1, Max: 100}, "casino_cut": {Min: 0, Max: 50}},
+		"lottery buy":              {"tickets": {Min: 1, Max: 1000}},
+		"wager propose":            {"amount": {Min: 0.01, Max: MaxCheesecoinInput, Cheesecoin: true}, "days": {Min: 1, Max: 365}},
+		"escrow create":            {"cheesecoin": {Min: 0.01, Max: MaxCheesecoinInput, Cheesecoin: true}, "days": {Min: 1, Max: 90}},
+		"campaign create":          {"goal": {Min: 0.01, Max: MaxCheesecoinInput, Cheesecoin: true}, "days": {Min: 1, Max: 90}},
+		"campaign pledge":          {"cheesecoin": {Min: 0.01, Max: MaxCheesecoinInput, Cheesecoin: true}},
 	}
 )
 
@@ -567,7 +2866,7 @@ func add_commands(session *discordgo.Session) {
 		index++
 	}
 	for id, account := range data.OrganisationAccounts {
-		all_account_choices[index] = &discordgo.ApplicationCommandOptionChoice{Name: account.Name + " (Organisation)", Value: id}
+		all_account_choices[index] = &discordgo.ApplicationCommandOptionChoice{Name: truncate(account.Name, MaxOrgNameLength) + " (Organisation)", Value: id}
 		index++
 	}
 	command := []*discordgo.ApplicationCommand{
@@ -644,6 +2943,469 @@ func add_commands(session *discordgo.Session) {
 					Required:    true,
 				},
 			},
+		}, {
+			Name:        "org_add_member",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Add a member to an organisation you own or change their role.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "organisation",
+					Description:  "The organisation (must be owned by you).",
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "member",
+					Description:  "The new member of the organisation",
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "role",
+					Description: "What the member is allowed to do.",
+					Required:    true,
+					Choices:     role_choices(),
+				},
+			},
+		}, {
+			Name:        "org_remove_member",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Remove a member from an organisation you own, or leave an organisation.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "organisation",
+					Description:  "The organisation.",
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "member",
+					Description:  "The member to remove",
+					Required:     true,
+					Autocomplete: true,
+				},
+			},
+		}, {
+			Name:        "org_members",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "List the members of an organisation and their roles.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "organisation",
+					Description:  "The organisation (you must be a member).",
+					Required:     true,
+					Autocomplete: true,
+				},
+			},
+		}, {
+			Name:        "org_set_limit",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Limit how much a member can pay from an organisation you own.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "organisation",
+					Description:  "The organisation (must be owned by you).",
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "member",
+					Description:  "The member to limit",
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionType(10), // Float
+					Name:        "daily",
+					Description: "The most cheesecoins the member can pay each day (0 for no limit).",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionType(10), // Float
+					Name:        "per_payment",
+					Description: "The most cheesecoins the member can pay at once (0 for no limit).",
+					Required:    true,
+				},
+			},
+		}, {
+			Name:        "org_set_approval",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Require a second member to approve large payments from an organisation you own.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "organisation",
+					Description:  "The organisation (must be owned by you).",
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionType(10), // Float
+					Name:        "threshold",
+					Description: "Payments over this amount need approval (0 to disable).",
+					Required:    true,
+				},
+			},
+		}, {
+			Name:        "payroll",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Manage the employees of an organisation.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "add",
+					Description: "Add an employee or change their salary (must be owned by you).",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "organisation",
+							Description:  "The organisation.",
+							Required:     true,
+							Autocomplete: true,
+						},
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "employee",
+							Description:  "The employee to pay",
+							Required:     true,
+							Autocomplete: true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionType(10), // Float
+							Name:        "salary",
+							Description: "Cheesecoins paid each time",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "interval_days",
+							Description: "Number of days between payments",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "remove",
+					Description: "Remove an employee (must be owned by you).",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "organisation",
+							Description:  "The organisation.",
+							Required:     true,
+							Autocomplete: true,
+						},
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "employee",
+							Description:  "The employee to remove",
+							Required:     true,
+							Autocomplete: true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "List the employees and their salaries.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "organisation",
+							Description:  "The organisation.",
+							Required:     true,
+							Autocomplete: true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "run",
+					Description: "Pay all of the employees now.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "organisation",
+							Description:  "The organisation.",
+							Required:     true,
+							Autocomplete: true,
+						},
+					},
+				},
+			},
+		}, {
+			Name:        "shares",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Issue, transfer and view shares in organisations.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "issue",
+					Description: "Issue new shares in an organisation you own.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "organisation",
+							Description:  "The organisation (must be owned by you).",
+							Required:     true,
+							Autocomplete: true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "shares",
+							Description: "The number of new shares",
+							Required:    true,
+						},
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "holder",
+							Description:  "Who recieves the new shares. Default is you",
+							Required:     false,
+							Autocomplete: true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "transfer",
+					Description: "Give some of your shares to another user.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "organisation",
+							Description:  "The organisation the shares are in.",
+							Required:     true,
+							Autocomplete: true,
+						},
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "recipiant",
+							Description:  "Recipiant of the shares",
+							Required:     true,
+							Autocomplete: true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "shares",
+							Description: "The number of shares",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "holdings",
+					Description: "View your shares.",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "vote",
+					Description: "Vote for who should own an organisation. A majority of shares offers them ownership.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "organisation",
+							Description:  "The organisation you have shares in.",
+							Required:     true,
+							Autocomplete: true,
+						},
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "candidate",
+							Description:  "Who you would like to own the organisation",
+							Required:     true,
+							Autocomplete: true,
+						},
+					},
+				},
+			},
+		}, {
+			Name:        "dividend",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Pay the shareholders of an organisation you own.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "organisation",
+					Description:  "The organisation (must be owned by you).",
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionType(10), // Float
+					Name:        "cheesecoin",
+					Description: "The total amount of cheesecoins to pay",
+					Required:    true,
+				},
+			},
+		}, {
+			Name:        "order",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Trade shares in organisations on the stock exchange.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "buy",
+					Description: "Buy shares. Your cheesecoins are held in escrow until the order is filled.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "organisation",
+							Description:  "The organisation to buy shares in.",
+							Required:     true,
+							Autocomplete: true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "shares",
+							Description: "The number of shares",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionType(10), // Float
+							Name:        "price",
+							Description: "The price per share. Default is the market price",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "sell",
+					Description: "Sell shares. Your shares are held in escrow until the order is filled.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "organisation",
+							Description:  "The organisation to sell shares in.",
+							Required:     true,
+							Autocomplete: true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "shares",
+							Description: "The number of shares",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionType(10), // Float
+							Name:        "price",
+							Description: "The price per share. Default is the market price",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "cancel",
+					Description: "Cancel an open order, returning anything held in escrow.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "order",
+							Description: "The number of the order (see /order list).",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "List your open orders.",
+				},
+			},
+		}, {
+			Name:        "market",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "View the prices of shares on the stock exchange.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "organisation",
+					Description:  "Show the order book and recent trades of this organisation.",
+					Required:     false,
+					Autocomplete: true,
+				},
+			},
+		}, {
+			Name:        "org_profile",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Set the public profile of an organisation you own.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "organisation",
+					Description:  "The organisation (must be owned by you).",
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "description",
+					Description: "What the organisation does",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "category",
+					Description: "The kind of organisation",
+					Required:    false,
+					Choices:     category_choices(),
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "logo_url",
+					Description: "A link to the logo of the organisation",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "public_balance",
+					Description: "If everyone can see the balance of the organisation",
+					Required:    false,
+				},
+			},
+		}, {
+			Name:        "org_info",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "View the profile of an organisation.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "organisation",
+					Description:  "The organisation.",
+					Required:     true,
+					Autocomplete: true,
+				},
+			},
+		}, {
+			Name:        "directory",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "List the organisations by category.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "category",
+					Description: "Only list organisations in this category",
+					Required:    false,
+					Choices:     category_choices(),
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "search",
+					Description: "Only list organisations with names matching this",
+					Required:    false,
+				},
+			},
 		}, {
 			Name:        "create_org",
 			Type:        discordgo.ChatApplicationCommand,
@@ -652,51 +3414,169 @@ func add_commands(session *discordgo.Session) {
 
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "name",
-					Description: "The name of the new organisation",
-					Required:    true,
+					Name:        "name",
+					Description: "The name of the new organisation",
+					Required:    true,
+				},
+			},
+		}, {
+			Name:        "delete_org",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Delete an organisation.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "organisation",
+					Description:  "The organisation you wish to delete (must be owned by you).",
+					Required:     true,
+					Autocomplete: true,
+				},
+			},
+		}, {
+			Name:        "answer_mp_rollcall",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Show you are active and get payed for the day if you are an MP.",
+		}, {
+			Name:        "economy",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Statistics about the economy and how they have changed.",
+		}, {
+			Name:        "chart",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Draws a chart of a balance or economy statistic.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "balance",
+					Description: "Chart the balance of one of your accounts.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "account",
+							Description:  "The organisation to chart (must be owned by you). Default is personal",
+							Required:     false,
+							Autocomplete: true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "period",
+							Description: "The number of days to chart. Default is 30",
+							Required:    false,
+							Choices:     period_choices(),
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "economy",
+					Description: "Chart a statistic about the economy.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "metric",
+							Description: "The statistic to chart.",
+							Required:    true,
+							Choices:     metric_choices(),
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "period",
+							Description: "The number of days to chart. Default is 30",
+							Required:    false,
+							Choices:     period_choices(),
+						},
+					},
+				},
+			},
+		}, {
+			Name:        "sudo_set_wealth_tax",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Set the wealth tax rate.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionType(10), // Float
+					Name:        "new_tax",
+					Description: "The new wealth tax rate (0% to 100%).",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "effective_date",
+					Description: "Schedule the change for a future date in the day/month/year format. Default is now.",
+					Required:    false,
+				},
+			},
+		}, {
+			Name:        "sudo_set_transaction_tax",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Set the transaction tax rate.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionType(10), // Float
+					Name:        "new_tax",
+					Description: "The new transaction tax rate (0% to 100%).",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "effective_date",
+					Description: "Schedule the change for a future date in the day/month/year format. Default is now.",
+					Required:    false,
 				},
 			},
 		}, {
-			Name:        "delete_org",
+			Name:        "sudo_set_sales_tax",
 			Type:        discordgo.ChatApplicationCommand,
-			Description: "Delete an organisation.",
+			Description: "Set the sales tax rate charged on payments to organisations.",
 			Options: []*discordgo.ApplicationCommandOption{
 				{
-					Type:         discordgo.ApplicationCommandOptionString,
-					Name:         "organisation",
-					Description:  "The organisation you wish to delete (must be owned by you).",
-					Required:     true,
-					Autocomplete: true,
+					Type:        discordgo.ApplicationCommandOptionType(10), // Float
+					Name:        "new_tax",
+					Description: "The new sales tax rate (0% to 100%).",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "effective_date",
+					Description: "Schedule the change for a future date in the day/month/year format. Default is now.",
+					Required:    false,
 				},
 			},
 		}, {
-			Name:        "answer_mp_rollcall",
-			Type:        discordgo.ChatApplicationCommand,
-			Description: "Show you are active and get payed for the day if you are an MP.",
-		}, {
-			Name:        "sudo_set_wealth_tax",
+			Name:        "sudo_set_winnings_tax",
 			Type:        discordgo.ChatApplicationCommand,
-			Description: "Set the wealth tax rate.",
+			Description: "Set the tax paid to the treasury on casino winnings.",
 			Options: []*discordgo.ApplicationCommandOption{
 				{
 					Type:        discordgo.ApplicationCommandOptionType(10), // Float
 					Name:        "new_tax",
-					Description: "The new wealth tax rate (0% to 100%).",
+					Description: "The new winnings tax rate (0% to 100%).",
 					Required:    true,
 				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "effective_date",
+					Description: "Schedule the change for a future date in the day/month/year format. Default is now.",
+					Required:    false,
+				},
 			},
 		}, {
-			Name:        "sudo_set_transaction_tax",
+			Name:        "sudo_set_lottery_tax",
 			Type:        discordgo.ChatApplicationCommand,
-			Description: "Set the transaction tax rate.",
+			Description: "Set the share of each lottery prize pool paid to the treasury.",
 			Options: []*discordgo.ApplicationCommandOption{
 				{
 					Type:        discordgo.ApplicationCommandOptionType(10), // Float
 					Name:        "new_tax",
-					Description: "The new transaction tax rate (0% to 100%).",
+					Description: "The new lottery tax rate (0% to 50%).",
 					Required:    true,
 				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "effective_date",
+					Description: "Schedule the change for a future date in the day/month/year format. Default is now.",
+					Required:    false,
+				},
 			},
 		}, {
 			Name:        "sudo_set_bank_holiday",
@@ -727,6 +3607,42 @@ func add_commands(session *discordgo.Session) {
 			Name:        "bank_holidays",
 			Type:        discordgo.ChatApplicationCommand,
 			Description: "List the bank holidays coming up soon.",
+		}, {
+			Name:        "sudo_set_ubi",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Set the universal basic income paid from the treasury. Can only be done by super user.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionType(10), // Float
+					Name:        "cheesecoin",
+					Description: "Amount paid to each active member (0 to disable).",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "interval_days",
+					Description: "Number of days between payments.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "active_days",
+					Description: "Members must have used the bot within this many days to be paid.",
+					Required:    true,
+				},
+			},
+		}, {
+			Name:        "sudo_set_org_fee",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Set the fee paid to the treasury to create an organisation. Can only be done by super user.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionType(10), // Float
+					Name:        "cheesecoin",
+					Description: "The creation fee (0 to disable).",
+					Required:    true,
+				},
+			},
 		}, {
 			Name:        "sudo_loan",
 			Type:        discordgo.ChatApplicationCommand,
@@ -750,12 +3666,24 @@ func add_commands(session *discordgo.Session) {
 			Name:        "sudo_set_interest_rate",
 			Type:        discordgo.ChatApplicationCommand,
 			Description: "Sets interest rate in percent. Can only be done by super user (i.e. head of bank).",
-			Options: []*discordgo.ApplicationCommandOption{{
-				Type:        discordgo.ApplicationCommandOptionType(10), // Float
-				Name:        "new_interest",
-				Description: "The new interest rate (0% to 100%).",
-				Required:    true,
-			}},
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionType(10), // Float
+					Name:        "new_interest",
+					Description: "The new interest rate (0% to 100%).",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "effective_date",
+					Description: "Schedule the change for a future date in the day/month/year format. Default is now.",
+					Required:    false,
+				},
+			},
+		}, {
+			Name:        "rates_history",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Lists the recent and scheduled changes to the tax and interest rates.",
 		}, {
 			Name:        "view_bank_loans",
 			Type:        discordgo.ChatApplicationCommand,
@@ -779,6 +3707,462 @@ func add_commands(session *discordgo.Session) {
 					Choices:     dice_choices(),
 				},
 			},
+		}, {
+			Name:        "casino_seed",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "View your casino seeds, or set a new client seed which reveals your old server seed.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "client_seed",
+					Description: "Your new client seed (any text you like).",
+					Required:    false,
+				},
+			},
+		}, {
+			Name:        "casino_verify",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Check a casino roll using revealed seeds.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "server_seed",
+					Description: "The revealed server seed.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "client_seed",
+					Description: "The client seed used with it.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "nonce",
+					Description: "The number of the roll.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "sides",
+					Description: "The number of possible results (6 for dice).",
+					Required:    false,
+				},
+			},
+		}, {
+			Name:        "blackjack",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Play a game of blackjack at the casino.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionType(10), // Float
+					Name:        "stake",
+					Description: "Amount of cheesecoins",
+					Required:    true,
+				},
+			},
+		}, {
+			Name:        "blackjack_rules",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Set the house rules for blackjack. Only avaliable to the owner of the casino.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "decks",
+					Description: "The number of decks in the shoe.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "hit_soft_17",
+					Description: "If the dealer draws another card on a soft 17.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionType(10), // Float
+					Name:        "blackjack_pays",
+					Description: "The winnings for a blackjack as a multiple of the stake (normally 1.5).",
+					Required:    true,
+				},
+			},
+		}, {
+			Name:        "roulette",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Bet on a spin of the roulette wheel at the casino.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "bets",
+					Description: "Bets and amounts such as: red 10, 17 2.5, dozen1 5",
+					Required:    true,
+				},
+			},
+		}, {
+			Name:        "roulette_set_edge",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Set the house edge on roulette winnings. Only avaliable to the owner of the casino.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionType(10), // Float
+					Name:        "edge",
+					Description: "The percentage taken off the standard odds.",
+					Required:    true,
+				},
+			},
+		}, {
+			Name:        "lottery",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Take part in the lottery run by the casino.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "start",
+					Description: "Open a new round of the lottery. Only avaliable to the owner of the casino.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionType(10), // Float
+							Name:        "ticket_price",
+							Description: "The price of each ticket.",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "hours",
+							Description: "The number of hours until the draw.",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "winners",
+							Description: "The number of winning tickets. Default is 1.",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionType(10), // Float
+							Name:        "casino_cut",
+							Description: "The percentage of the prize pool kept by the casino. Default is 10%.",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "buy",
+					Description: "Buy tickets in the open round.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "tickets",
+							Description: "The number of tickets.",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "status",
+					Description: "View the open round and the results of the last draw.",
+				},
+			},
+		}, {
+			Name:        "wager",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Make a bet with another user, held in escrow until it is decided.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "propose",
+					Description: "Propose a wager, which the other user must accept within a day.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "user",
+							Description:  "The user to bet against.",
+							Required:     true,
+							Autocomplete: true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionType(10), // Float
+							Name:        "amount",
+							Description: "The amount each of you stakes.",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "terms",
+							Description: "What the wager is on.",
+							Required:    true,
+						},
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "arbiter",
+							Description:  "Someone to pick the winner if you disagree.",
+							Required:     false,
+							Autocomplete: true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "days",
+							Description: "The days after accepting before the stakes are refunded if there is no winner. Default is 7.",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "View the wagers you are taking part in or arbitrating.",
+				},
+			},
+		}, {
+			Name:        "escrow",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Hold a payment with the bank until you are happy to release it.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "create",
+					Description: "Hold a payment in escrow.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "recipiant",
+							Description:  "The account the payment is for.",
+							Required:     true,
+							Autocomplete: true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionType(10), // Float
+							Name:        "cheesecoin",
+							Description: "The amount to hold.",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "terms",
+							Description: "What the payment is for.",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "days",
+							Description: "The days until the payment is refunded if it is not released or disputed.",
+							Required:    true,
+						},
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "from_organisation",
+							Description:  "The organisation to pay from (default is your personal account).",
+							Required:     false,
+							Autocomplete: true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "release",
+					Description: "Release a payment you made to the recipiant.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "escrow",
+							Description:  "The escrow payment.",
+							Required:     true,
+							Autocomplete: true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "refund",
+					Description: "Give back a payment made to you.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "escrow",
+							Description:  "The escrow payment.",
+							Required:     true,
+							Autocomplete: true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "dispute",
+					Description: "Ask a super user to decide a payment, which is refunded if they do not decide within 7 days.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "escrow",
+							Description:  "The escrow payment.",
+							Required:     true,
+							Autocomplete: true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "reason",
+							Description: "Why the payment is disputed.",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "View your escrow payments, and disputed payments if you are a super user.",
+				},
+			},
+		}, {
+			Name:        "campaign",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Raise money for an organisation from backers, all or nothing.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "create",
+					Description: "Start a campaign for an organisation you own.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "organisation",
+							Description:  "The organisation raising money (must be owned by you).",
+							Required:     true,
+							Autocomplete: true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "title",
+							Description: "What the money is for.",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionType(10), // Float
+							Name:        "goal",
+							Description: "The amount which must be pledged for the campaign to succeed.",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "days",
+							Description: "The number of days the campaign runs for.",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "pledge",
+					Description: "Pledge to a campaign from your personal account.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "campaign",
+							Description:  "The campaign.",
+							Required:     true,
+							Autocomplete: true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionType(10), // Float
+							Name:        "cheesecoin",
+							Description: "The amount to pledge.",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "cancel",
+					Description: "Cancel a campaign for an organisation you own and refund every pledge.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "campaign",
+							Description:  "The campaign.",
+							Required:     true,
+							Autocomplete: true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "View the open campaigns.",
+				},
+			},
+		}, {
+			Name:        "gambling_limit",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "View or set the most you can lose at the casino each day.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionType(10), // Float
+					Name:        "daily_loss",
+					Description: "Your new daily loss limit (0 for no limit). Raising it takes 24 hours.",
+					Required:    false,
+				},
+			},
+		}, {
+			Name:        "self_exclude",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Exclude yourself from all casino games. This cannot be undone.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "duration",
+					Description: "How long to exclude yourself for.",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "1 day", Value: 1},
+						{Name: "1 week", Value: 7},
+						{Name: "1 month", Value: 30},
+						{Name: "6 months", Value: 182},
+						{Name: "1 year", Value: 365},
+					},
+				},
+			},
+		}, {
+			Name:        "sudo_gambling_policy",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Set the bank's gambling policy. Can only be done by super user.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "min_bet_interval",
+					Description: "The least number of seconds between bets.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "block_overdue",
+					Description: "If people with overdue loans are stopped from gambling.",
+					Required:    true,
+				},
+			},
+		}, {
+			Name:        "casino_report",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "View the performance of the casino. Only avaliable to members of the casino.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "period",
+					Description: "The number of days to report on. Default is 7 days.",
+					Required:    false,
+					Choices:     period_choices(),
+				},
+			},
+		}, {
+			Name:        "casino_digest",
+			Type:        discordgo.ChatApplicationCommand,
+			Description: "Choose between a message for each win or a daily report. Only avaliable to the owner of the casino.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "daily",
+					Description: "If you want a daily report instead of a message for each win.",
+					Required:    true,
+				},
+			},
 		}, {
 			Name:        "gambling_set_returns",
 			Type:        discordgo.ChatApplicationCommand,
@@ -792,6 +4176,16 @@ func add_commands(session *discordgo.Session) {
 		},
 	}
 
+	// Give Discord the limits on numeric options too, so that it rejects values outside them before they are sent
+	for _, c := range command {
+		set_option_limits(c.Name, c.Options)
+		for _, option := range c.Options {
+			if option.Type == discordgo.ApplicationCommandOptionSubCommand {
+				set_option_limits(c.Name+" "+option.Name, option.Options)
+			}
+		}
+	}
+
 	_, err := session.ApplicationCommandBulkOverwrite(session.State.User.ID, "", command)
 
 	if err != nil {
@@ -799,6 +4193,61 @@ func add_commands(session *discordgo.Session) {
 	}
 }
 
+// Sets the minimum and maximum values of the options of a command from `commandLimits`.
+// Integer options can only be whole numbers, so their limits are rounded inwards.
+func set_option_limits(command string, options []*discordgo.ApplicationCommandOption) {
+	for _, option := range options {
+		limit, ok := commandLimits[command][option.Name]
+		if !ok {
+			continue
+		}
+		min, max := limit.Min, limit.Max
+		if option.Type == discordgo.ApplicationCommandOptionInteger {
+			min, max = math.Ceil(min), math.Floor(max)
+		}
+		option.MinValue = &min
+		option.MaxValue = max
+	}
+}
+
+// Finds the name used to look up a command in `commandAutocomplete` and `commandLimits` and the options given to it.
+// For subcommands this is the command and subcommand name separated by a space.
+func command_options(interaction_data discordgo.ApplicationCommandInteractionData) (string, []*discordgo.ApplicationCommandInteractionDataOption) {
+	if len(interaction_data.Options) > 0 && interaction_data.Options[0].Type == discordgo.ApplicationCommandOptionSubCommand {
+		return interaction_data.Name + " " + interaction_data.Options[0].Name, interaction_data.Options[0].Options
+	}
+	return interaction_data.Name, interaction_data.Options
+}
+
+// Checks the numeric options of a command against the limits in `commandLimits`.
+// Returns an error string which is empty if all of the options are valid.
+func validate_options(command string, options []*discordgo.ApplicationCommandInteractionDataOption) string {
+	for _, option := range options {
+		limit, ok := commandLimits[command][option.Name]
+		if !ok {
+			continue
+		}
+		value, ok := option.Value.(float64)
+		if !ok {
+			continue
+		}
+
+		if !(value >= limit.Min && value <= limit.Max) {
+			return fmt.Sprint("**ERROR:** `", option.Name, "` must be between ", strconv.FormatFloat(limit.Min, 'f', -1, 64), " and ", strconv.FormatFloat(limit.Max, 'f', -1, 64), ".")
+		}
+		if limit.Cheesecoin && math.Abs(value*100-math.Round(value*100)) > 1e-6 {
+			return fmt.Sprint("**ERROR:** `", option.Name, "` cannot have more than 2 decimal places.")
+		}
+	}
+	return ""
+}
+
+// Converts a cheesecoin option to an integer number of hundredths of a cheesecoin, rounding rather than truncating
+func cheesecoin_option(option *discordgo.ApplicationCommandInteractionDataOption) int {
+	value, _ := option.Value.(float64)
+	return int(math.Round(value * 100))
+}
+
 // Checks if a time is in a list of times
 func contains_int64(s []int64, value int64) bool {
 	for _, v := range s {
@@ -848,6 +4297,24 @@ func months_choices() []*discordgo.ApplicationCommandOptionChoice {
 	return result
 }
 
+// Generates a command option choice for the roles of an organisation member
+func role_choices() []*discordgo.ApplicationCommandOptionChoice {
+	result := make([]*discordgo.ApplicationCommandOptionChoice, len(role_names))
+	for i, v := range role_names {
+		result[i] = &discordgo.ApplicationCommandOptionChoice{Name: v, Value: i}
+	}
+	return result
+}
+
+// Generates a command option choice for the categories of organisation
+func category_choices() []*discordgo.ApplicationCommandOptionChoice {
+	result := make([]*discordgo.ApplicationCommandOptionChoice, len(org_categories))
+	for i, v := range org_categories {
+		result[i] = &discordgo.ApplicationCommandOptionChoice{Name: v, Value: v}
+	}
+	return result
+}
+
 // Generates a command option choice for numbers from 1 - 6
 func dice_choices() []*discordgo.ApplicationCommandOptionChoice {
 	result := make([]*discordgo.ApplicationCommandOptionChoice, 6)
@@ -857,6 +4324,24 @@ func dice_choices() []*discordgo.ApplicationCommandOptionChoice {
 	return result
 }
 
+// Generates a command option choice for the periods which can be charted
+func period_choices() []*discordgo.ApplicationCommandOptionChoice {
+	result := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, days := range []int{7, 30, 90, MaxChartDays} {
+		result = append(result, &discordgo.ApplicationCommandOptionChoice{Name: fmt.Sprint(days, " days"), Value: days})
+	}
+	return result
+}
+
+// Generates a command option choice for each of the economy metrics
+func metric_choices() []*discordgo.ApplicationCommandOptionChoice {
+	result := make([]*discordgo.ApplicationCommandOptionChoice, len(economy_metrics))
+	for i, metric := range economy_metrics {
+		result[i] = &discordgo.ApplicationCommandOptionChoice{Name: metric.Name, Value: metric.Id}
+	}
+	return result
+}
+
 // Read the json file - called on init
 func read_data() {
 	// Read the file
@@ -870,10 +4355,103 @@ func read_data() {
 		log.Fatal(err)
 	}
 
+	// Organisations from before roles were added are owned by everyone who had them
+	for id, usr := range data.Users {
+		for _, org := range usr.Organisations {
+			account, ok := data.OrganisationAccounts[org]
+			if !ok {
+				continue
+			}
+			if account.Members == nil {
+				account.Members = map[string]int{}
+			}
+			if _, ok := account.Members[id]; !ok {
+				account.Members[id] = RoleOwner
+			}
+		}
+	}
+	for _, account := range data.OrganisationAccounts {
+		if account.Members == nil {
+			account.Members = map[string]int{}
+		}
+		if account.Limits == nil {
+			account.Limits = map[string]*SpendingLimit{}
+		}
+		if account.Payroll == nil {
+			account.Payroll = map[string]*Employee{}
+		}
+		if account.Shares == nil {
+			account.Shares = map[string]int{}
+		}
+		if account.Votes == nil {
+			account.Votes = map[string]string{}
+		}
+	}
+
+	// Rate changes made before the effective date was kept separately only have the time they applied
+	for _, change := range append(data.RateHistory, data.ScheduledRates...) {
+		if change.Effective.IsZero() {
+			change.Effective = change.Time
+		}
+	}
+
+	if data.TransferOffers == nil {
+		data.TransferOffers = map[string]*TransferOffer{}
+	}
+	if data.PendingPayments == nil {
+		data.PendingPayments = map[string]*PendingPayment{}
+	}
+	if data.Escrow == nil {
+		data.Escrow = &Account{Name: "Escrow", Balance: 0, Loans: []*Loan{}}
+	}
+	if data.ArchivedOrganisations == nil {
+		data.ArchivedOrganisations = map[string]*Account{}
+	}
+	if data.CasinoSeeds == nil {
+		data.CasinoSeeds = map[string]*CasinoSeed{}
+	}
+	if data.BlackjackRules == nil {
+		data.BlackjackRules = &BlackjackRules{Decks: 6, HitSoft17: false, BlackjackPays: 1.5}
+	}
+	if data.BlackjackGames == nil {
+		data.BlackjackGames = map[string]*BlackjackGame{}
+	}
+	// Fix the lottery tax of a round opened before it was saved with the round, keeping it within what is left of the pool
+	if round := open_lottery(); round != nil && round.LotteryTax == 0 {
+		round.LotteryTax = math.Min(data.LotteryTax, 100-round.CasinoCut)
+	}
+	if data.Wagers == nil {
+		data.Wagers = map[string]*Wager{}
+	}
+	if data.EscrowPayments == nil {
+		data.EscrowPayments = map[string]*EscrowPayment{}
+	}
+	// Give disputes raised before they had a deadline the full time to be decided
+	for _, escrow := range data.EscrowPayments {
+		if escrow.Disputed && escrow.DisputeDeadline.IsZero() {
+			escrow.DisputeDeadline = time.Now().Add(EscrowDisputeTimeout)
+		}
+	}
+	if data.Campaigns == nil {
+		data.Campaigns = map[string]*Campaign{}
+	}
+
 	// Assign special organisations
 	treasury = "1000"
 	bank = "1003"
 	casino = "1023"
+
+	// Reserve the casino's cover for blackjack games started before it was held in escrow
+	for _, game := range data.BlackjackGames {
+		if game.Cover == 0 {
+			game.Cover = blackjack_worst_case(game.Stake)
+			if game.Cover > data.OrganisationAccounts[casino].Balance {
+				game.Cover = data.OrganisationAccounts[casino].Balance
+			}
+			data.OrganisationAccounts[casino].Balance -= game.Cover
+			data.Escrow.Balance += game.Cover
+		}
+	}
 }
 
 // Save the json file - called on shutdown
@@ -889,49 +4467,593 @@ func save_data() {
 		log.Fatal(err)
 	}
 
-	fmt.Print("Saving....")
+	fmt.Print("Saving....")
+}
+
+func periodic_save() {
+	for range time.Tick(time.Minute * 1) {
+		data_mutex.Lock()
+		save_data()
+		data_mutex.Unlock()
+	}
+}
+
+// Applies welth tax to a specific account returning the log information for the user
+func apply_wealth_tax_account(account *Account, name string) string {
+	tax := int(math.Ceil(float64(account.Balance) * data.WealthTax / 100))
+	account.Balance -= tax
+	data.OrganisationAccounts[treasury].Balance += tax
+	data.DayTaxCollected += tax
+	return fmt.Sprintf("\n%-20s %s", name+":", format_cheesecoins(tax))
+}
+
+// Applies wealth tax. Called every day
+func apply_wealth_tax(session *discordgo.Session) {
+	fmt.Print("Wealth tax.")
+
+	// Organisations are taxed once, even if they have several members
+	org_results := map[string]string{}
+	for id, account := range data.OrganisationAccounts {
+		if id != treasury {
+			org_results[id] = apply_wealth_tax_account(account, account.Name)
+		}
+	}
+
+	for id, usr := range data.Users {
+		result := apply_wealth_tax_account(data.PersonalAccounts[usr.PersonalAccount], "Personal")
+		for _, org := range usr.Organisations {
+			result += org_results[org]
+		}
+
+		send_embed("Wealth Tax", session, id,
+			fmt.Sprintf("Wealth tax has been applied at `%.2f%%`.\n\n**Payments**\n```%s\n```", data.WealthTax, result),
+			[]*discordgo.MessageEmbedField{})
+
+		fmt.Println(result) // Probably send this to the user to notify them of the tax
+	}
+}
+
+func check_wealth_tax(session *discordgo.Session) {
+	for range time.Tick(time.Minute * 1) {
+		data_mutex.Lock()
+		if time.Since(data.LastWealthTax).Hours() > 20 {
+			data.LastWealthTax = data.LastWealthTax.Add(time.Hour * 24)
+			apply_wealth_tax(session)
+		}
+		data_mutex.Unlock()
+	}
+}
+
+// Calculates the gini coefficient of the personal account balances (0 is perfect equality, 1 is perfect inequality)
+func gini_coefficient() float64 {
+	balances := []int{}
+	for _, account := range data.PersonalAccounts {
+		balances = append(balances, account.Balance)
+	}
+	sort.Ints(balances)
+
+	total, weighted := 0, 0
+	for i, balance := range balances {
+		total += balance
+		weighted += (i + 1) * balance
+	}
+	if total <= 0 {
+		return 0
+	}
+
+	n := float64(len(balances))
+	return 2*float64(weighted)/(n*float64(total)) - (n+1)/n
+}
+
+// Records the current state of the economy, including the transactions since the last snapshot
+func economy_snapshot() *EconomySnapshot {
+	active, loans := 0, 0
+	for _, usr := range data.Users {
+		if time.Since(usr.LastActive) < time.Hour*24*7 {
+			active++
+		}
+	}
+	for _, account := range data.PersonalAccounts {
+		for _, loan := range account.Loans {
+			loans += loan.AmountDue
+		}
+	}
+	for _, account := range data.OrganisationAccounts {
+		for _, loan := range account.Loans {
+			loans += loan.AmountDue
+		}
+	}
+
+	balances := map[string]int{}
+	for id, account := range data.PersonalAccounts {
+		balances[id] = account.Balance
+	}
+	for id, account := range data.OrganisationAccounts {
+		balances[id] = account.Balance
+	}
+
+	return &EconomySnapshot{
+		Time:              time.Now(),
+		MoneySupply:       total_currency(),
+		Treasury:          data.OrganisationAccounts[treasury].Balance,
+		TaxCollected:      data.DayTaxCollected,
+		TransactionVolume: data.DayTransactionVolume,
+		Transactions:      data.DayTransactions,
+		ActiveAccounts:    active,
+		OutstandingLoans:  loans,
+		Gini:              gini_coefficient(),
+		Balances:          balances,
+	}
+}
+
+// Finds the latest snapshot taken before a time. Returns nil if there is none.
+func snapshot_before(before time.Time) *EconomySnapshot {
+	var result *EconomySnapshot
+	for _, snapshot := range data.Snapshots {
+		if snapshot.Time.After(before) {
+			break
+		}
+		result = snapshot
+	}
+	return result
+}
+
+// Formats a row of the economy table, with the change since the week and month old snapshots
+func format_economy_row(name string, current *EconomySnapshot, week *EconomySnapshot, month *EconomySnapshot, value func(*EconomySnapshot) float64) string {
+	change := func(old *EconomySnapshot) string {
+		if old == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%+.2f", value(current)-value(old))
+	}
+	return fmt.Sprintf("\n%-20s %12.2f %10s %10s", name, value(current), change(week), change(month))
+}
+
+// Records a snapshot of the economy once per day, resetting the daily counters
+func check_snapshots() {
+	for range time.Tick(time.Minute * 1) {
+		data_mutex.Lock()
+		if len(data.Snapshots) == 0 || time.Since(data.Snapshots[len(data.Snapshots)-1].Time).Hours() >= 24 {
+			data.Snapshots = append(data.Snapshots, economy_snapshot())
+			data.DayTaxCollected = 0
+			data.DayTransactionVolume = 0
+			data.DayTransactions = 0
+
+			// Account balances are too old to be charted, so stop storing them
+			for _, snapshot := range data.Snapshots {
+				if time.Since(snapshot.Time) > time.Hour*24*MaxChartDays {
+					snapshot.Balances = nil
+				}
+			}
+		}
+		data_mutex.Unlock()
+	}
+}
+
+// Finds the value of a rate from its name
+func rate_value(rate string) *float64 {
+	switch rate {
+	case RateWealthTax:
+		return &data.WealthTax
+	case RateTransactionTax:
+		return &data.TransactionTax
+	case RateSalesTax:
+		return &data.SalesTax
+	case RateInterest:
+		return &data.LoanInterest
+	case RateLotteryTax:
+		return &data.LotteryTax
+	case RateWinningsTax:
+		return &data.WinningsTax
+	}
+	return nil
+}
+
+// Handles a command setting a rate, either changing it now or scheduling the change for the effective date
+func set_rate(data_handler HandlerData, title string, rate string) {
+	change := &RateChange{Rate: rate, NewValue: data_handler.interaction_data.Options[0].Value.(float64), User: data_handler.user.ID, Time: time.Now(), Effective: time.Now()}
+
+	// Transaction tax and sales tax are both taken from the same payment, so together they cannot be more than all of it
+	other := ""
+	switch rate {
+	case RateTransactionTax:
+		other = RateSalesTax
+	case RateSalesTax:
+		other = RateTransactionTax
+	}
+	if other != "" {
+		highest := *rate_value(other)
+		for _, scheduled := range data.ScheduledRates {
+			if scheduled.Rate == other && scheduled.NewValue > highest {
+				highest = scheduled.NewValue
+			}
+		}
+		if change.NewValue+highest > 100 {
+			create_embed(title, data_handler.session, data_handler.interaction, fmt.Sprint("**ERROR:** The ", strings.ToLower(rate), " and ", strings.ToLower(other), " (up to ", highest, "%) cannot add up to more than 100%"), []*discordgo.MessageEmbedField{})
+			return
+		}
+	}
+
+	if len(data_handler.interaction_data.Options) > 1 {
+		effective_date, err := time.ParseInLocation("2/1/2006", data_handler.interaction_data.Options[1].StringValue(), time.Local)
+		if err != nil {
+			create_embed(title, data_handler.session, data_handler.interaction, "**ERROR:** The effective date must be in the day/month/year format e.g. 2/12/2021", []*discordgo.MessageEmbedField{})
+			return
+		}
+		if !effective_date.After(time.Now()) {
+			create_embed(title, data_handler.session, data_handler.interaction, "**ERROR:** The effective date must be in the future", []*discordgo.MessageEmbedField{})
+			return
+		}
+
+		change.Effective = effective_date
+		data.ScheduledRates = append(data.ScheduledRates, change)
+
+		create_embed(title, data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully scheduled ", strings.ToLower(rate), " to change to ", change.NewValue, "% on <t:", effective_date.Unix(), ":D>."), []*discordgo.MessageEmbedField{})
+		return
+	}
+
+	// Reply before the change is announced to every user, which queues a message for each of them
+	create_embed(title, data_handler.session, data_handler.interaction, fmt.Sprint("Sucessfully set ", strings.ToLower(rate), " to ", change.NewValue, "%."), []*discordgo.MessageEmbedField{})
+
+	apply_rate_change(change, data_handler.session)
+}
+
+// Changes a rate, records the change in the history and announces it to all users
+func apply_rate_change(change *RateChange, session *discordgo.Session) {
+	value := rate_value(change.Rate)
+	change.OldValue = *value
+	*value = change.NewValue
+	data.RateHistory = append(data.RateHistory, change)
+
+	for id := range data.Users {
+		send_embed("Rate Change", session, id, fmt.Sprintf("The %s has changed from `%.2f%%` to `%.2f%%`.", strings.ToLower(change.Rate), change.OldValue, change.NewValue), []*discordgo.MessageEmbedField{})
+	}
+}
+
+// Applies any scheduled rate changes once their effective date has passed
+func check_scheduled_rates(session *discordgo.Session) {
+	for range time.Tick(time.Minute * 1) {
+		data_mutex.Lock()
+		remaining := []*RateChange{}
+		for _, change := range data.ScheduledRates {
+			if time.Now().After(change.Effective) {
+				apply_rate_change(change, session)
+			} else {
+				remaining = append(remaining, change)
+			}
+		}
+		data.ScheduledRates = remaining
+		data_mutex.Unlock()
+	}
+}
+
+// Checks that a payout from an organisation which is not made through /pay, such as a dividend, is not large enough to need a second member's approval.
+// Returns an error string which is empty if the payout is allowed.
+func check_approval_threshold(account *Account, amount int) string {
+	if account.ApprovalThreshold > 0 && amount > account.ApprovalThreshold {
+		return fmt.Sprint("**ERROR:** Payments over ", format_cheesecoins(account.ApprovalThreshold), " from ", account.Name, " need approval by a second member, so this cannot be paid at once. Pay a smaller amount or use /pay.")
+	}
+	return ""
+}
+
+// Checks if a member can pay an amount from an organisation without going over their spending limits.
+// Returns an error string which is empty if the payment is allowed.
+func check_spending_limit(account *Account, member string, amount int) string {
+	limit, ok := account.Limits[member]
+	if !ok || account.Members[member] == RoleOwner {
+		return ""
+	}
+	if limit.PerTransaction > 0 && amount > limit.PerTransaction {
+		return fmt.Sprint("**ERROR:** You can only pay up to ", format_cheesecoins(limit.PerTransaction), " at once from ", account.Name)
+	}
+
+	spent := limit.Spent
+	if !same_day(limit.SpentOn, time.Now()) {
+		spent = 0
+	}
+	if limit.Daily > 0 && spent+amount > limit.Daily {
+		return fmt.Sprint("**ERROR:** You can only pay ", format_cheesecoins(limit.Daily), " per day from ", account.Name, " and have already paid ", format_cheesecoins(spent), " today")
+	}
+	return ""
+}
+
+// Adds a payment to the amount a member has spent today from an organisation
+func record_spending(account *Account, member string, amount int) {
+	limit, ok := account.Limits[member]
+	if !ok {
+		return
+	}
+	if !same_day(limit.SpentOn, time.Now()) {
+		limit.Spent = 0
+	}
+	limit.Spent += amount
+	limit.SpentOn = time.Now()
+}
+
+func same_day(a time.Time, b time.Time) bool {
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+// Formats a spending limit, where zero means no limit
+func format_limit(limit int) string {
+	if limit == 0 {
+		return "no limit"
+	}
+	return format_cheesecoins(limit)
+}
+
+// Removes payments which have not been approved in time
+func check_pending_payments(session *discordgo.Session) {
+	for range time.Tick(time.Minute * 1) {
+		data_mutex.Lock()
+		for id, payment := range data.PendingPayments {
+			if time.Now().After(payment.Expires) {
+				delete(data.PendingPayments, id)
+				send_embed("Payment", session, payment.Requester, fmt.Sprint("Your payment of ", format_cheesecoins(payment.Amount), " was not approved in time and has been cancelled."), []*discordgo.MessageEmbedField{})
+			}
+		}
+		data_mutex.Unlock()
+	}
+}
+
+// Finds the shares each holder has in an organisation, including shares they have put up for sale which are held in escrow by their orders
+func shareholdings(account *Account) map[string]int {
+	result := map[string]int{}
+	for holder, held := range account.Shares {
+		result[holder] += held
+	}
+	for _, order := range data.Orders {
+		if !order.Buy && data.OrganisationAccounts[order.Organisation] == account {
+			result[order.User] += order.Shares
+		}
+	}
+	return result
+}
+
+// Finds the number of shares a user has in an organisation, including shares they have put up for sale
+func held_shares(account *Account, user string) int {
+	return shareholdings(account)[user]
+}
+
+// Finds the number of shares that an organisation has issued
+func total_shares(account *Account) int {
+	total := 0
+	for _, held := range shareholdings(account) {
+		total += held
+	}
+	return total
+}
+
+// Moves shares in an organisation from one user to another
+func transfer_shares(account *Account, from string, to string, amount int) {
+	account.Shares[from] -= amount
+	account.Shares[to] += amount
+	if account.Shares[from] == 0 {
+		delete(account.Shares, from)
+	}
+}
+
+// Reduces a name to lower case letters and digits, without a leading "the", so that similar names can be compared
+func name_key(name string) string {
+	result := strings.Builder{}
+	for _, r := range strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), "the ") {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			result.WriteRune(r)
+		}
+	}
+	return result.String()
+}
+
+// Normalises the whitespace in a new organisation name and checks that it is not too long, reserved or already taken.
+// The organisation being renamed (if any) is ignored when checking for duplicates.
+// Returns the normalised name and an error string, which is empty if the name is valid.
+func validate_org_name(name string, renaming string) (string, string) {
+	name = strings.Join(strings.Fields(name), " ")
+	key := name_key(name)
+
+	if key == "" {
+		return name, "**ERROR:** The name must contain at least one letter or digit"
+	}
+	if utf8.RuneCountInString(name) > MaxOrgNameLength {
+		return name, fmt.Sprint("**ERROR:** The name cannot be longer than ", MaxOrgNameLength, " characters")
+	}
+	for _, reserved := range reserved_org_names {
+		if key == name_key(reserved) {
+			return name, fmt.Sprint("**ERROR:** ", name, " is a reserved name")
+		}
+	}
+	for id, account := range data.OrganisationAccounts {
+		if id != renaming && name_key(account.Name) == key {
+			return name, fmt.Sprint("**ERROR:** There is already an organisation called ", account.Name)
+		}
+	}
+	for _, account := range data.PersonalAccounts {
+		if name_key(account.Name) == key {
+			return name, fmt.Sprint("**ERROR:** There is already a person called ", account.Name)
+		}
+	}
+	return name, ""
+}
+
+// Finds the category of an organisation, which is "Other" if it has not been set
+func organisation_category(account *Account) string {
+	if account.Category == "" {
+		return "Other"
+	}
+	return account.Category
+}
+
+// Shortens a string to a maximum number of characters, adding an ellipsis if anything was removed
+func truncate(text string, length int) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	return string(runes[:length-1]) + "…"
+}
+
+// Finds the discord ids of the owners of an organisation
+func org_owners(account *Account) []string {
+	result := []string{}
+	for id, role := range account.Members {
+		if role == RoleOwner {
+			result = append(result, id)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Pays employees of an organisation as one batch. If the organisation cannot afford to pay all of them then nobody is paid.
+// Returns if the payroll was sucsessful and a summary for the owner.
+func run_payroll(session *discordgo.Session, org string, employees []string) (bool, string) {
+	account := data.OrganisationAccounts[org]
+
+	total := 0
+	for _, id := range employees {
+		total += account.Payroll[id].Salary
+	}
+	if account.Balance < total {
+		account.PayrollFailed = time.Now()
+		return false, fmt.Sprint("**ERROR:** ", account.Name, " needs ", format_cheesecoins(total), " to pay ", len(employees), " employee(s) but has only ", format_cheesecoins(account.Balance), ". Nobody has been paid.")
+	}
+
+	summary := fmt.Sprint("Sucessfully paid the employees of ", account.Name, ".\n```")
+	paid := 0
+	for _, id := range employees {
+		employee := account.Payroll[id]
+		sucsess, err, tax, _ := transaction(employee.Salary, account, data.PersonalAccounts[data.Users[id].PersonalAccount], account.Name, nil, nil)
+		if !sucsess {
+			account.PayrollFailed = time.Now()
+			summary += fmt.Sprintf("\n%-20s %s", format_user(id)+":", err)
+			continue
+		}
+		employee.LastPaid = time.Now()
+		paid += employee.Salary
+
+		send_embed("Payslip", session, id, fmt.Sprint("You have been paid by ", account.Name, ".\n```\nGross             ", format_cheesecoins(employee.Salary),
+			"\nTransaction Tax - ", format_cheesecoins(tax), "\nNet             = ", format_cheesecoins(employee.Salary-tax), "\n```"), []*discordgo.MessageEmbedField{})
+		summary += fmt.Sprintf("\n%-20s %s", format_user(id)+":", format_cheesecoins(employee.Salary))
+	}
+	summary += fmt.Sprintf("\n\n%-20s %s\n```", "Total:", format_cheesecoins(paid))
+
+	return true, summary
+}
+
+// Runs the payroll of each organisation for the employees who are due to be paid.
+// A failed payroll is retried a day later.
+func check_payroll(session *discordgo.Session) {
+	for range time.Tick(time.Minute * 1) {
+		data_mutex.Lock()
+		for id, account := range data.OrganisationAccounts {
+			if time.Since(account.PayrollFailed) < time.Hour*24 {
+				continue
+			}
+
+			due := []string{}
+			for employee_id, employee := range account.Payroll {
+				if time.Since(employee.LastPaid) >= time.Hour*24*time.Duration(employee.Interval) {
+					due = append(due, employee_id)
+				}
+			}
+			if len(due) == 0 {
+				continue
+			}
+
+			_, summary := run_payroll(session, id, due)
+			for _, owner := range org_owners(account) {
+				send_embed("Payroll", session, owner, summary, []*discordgo.MessageEmbedField{})
+			}
+		}
+		data_mutex.Unlock()
+	}
+}
+
+// Sends the new owner of an organisation the offer, with buttons to accept or decline
+func send_transfer_offer(session *discordgo.Session, offer_id string) {
+	offer := data.TransferOffers[offer_id]
+	description := fmt.Sprint(format_user(offer.From), " would like to transfer ", data.OrganisationAccounts[offer.Organisation].Name, " to you.")
+	if offer.ShareholderVote {
+		description = fmt.Sprint("The shareholders of ", data.OrganisationAccounts[offer.Organisation].Name, " have voted for you to own it. Every other owner will become a viewer.")
+	}
+	send_buttons("Organisation transfer offer", session, offer.To, fmt.Sprint(description, " The offer expires <t:", offer.Expires.Unix(), ":R>."),
+		[]discordgo.MessageComponent{
+			discordgo.Button{Label: "Accept", Style: discordgo.SuccessButton, CustomID: "transfer_org_accept:" + offer_id},
+			discordgo.Button{Label: "Decline", Style: discordgo.DangerButton, CustomID: "transfer_org_decline:" + offer_id},
+		})
 }
 
-func periodic_save() {
+// Removes organisation transfer offers which have not been answered in time
+func check_transfer_offers(session *discordgo.Session) {
 	for range time.Tick(time.Minute * 1) {
-		save_data()
+		data_mutex.Lock()
+		for id, offer := range data.TransferOffers {
+			if time.Now().After(offer.Expires) {
+				delete(data.TransferOffers, id)
+
+				organisation_name := "an organisation"
+				if account, ok := data.OrganisationAccounts[offer.Organisation]; ok {
+					organisation_name = account.Name
+				}
+				if offer.ShareholderVote {
+					send_embed("Transfer organisation", session, offer.To, fmt.Sprint("The offer from the shareholders to transfer ", organisation_name, " to you has expired."), []*discordgo.MessageEmbedField{})
+					continue
+				}
+				send_embed("Transfer organisation", session, offer.From, fmt.Sprint("Your offer to transfer ", organisation_name, " to ", format_user(offer.To), " has expired."), []*discordgo.MessageEmbedField{})
+				send_embed("Transfer organisation", session, offer.To, fmt.Sprint("The offer from ", format_user(offer.From), " to transfer ", organisation_name, " to you has expired."), []*discordgo.MessageEmbedField{})
+			}
+		}
+		data_mutex.Unlock()
 	}
 }
 
-// Applies welth tax to a specific account returning the log information for the user
-func apply_wealth_tax_account(account *Account, name string) string {
-	tax := int(math.Ceil(float64(account.Balance) * data.WealthTax / 100))
-	account.Balance -= tax
-	data.OrganisationAccounts[treasury].Balance += tax
-	return fmt.Sprintf("\n%-20s %s", name+":", format_cheesecoins(tax))
-}
+// Pays universal basic income from the treasury to every recently active user.
+// If the treasury cannot afford the full amount the payment is pro-rated, or skipped if nothing can be paid.
+func apply_ubi(session *discordgo.Session) {
+	fmt.Print("Universal basic income.")
 
-// Applies wealth tax. Called every day
-func apply_wealth_tax(session *discordgo.Session) {
-	fmt.Print("Wealth tax.")
+	active_since := time.Now().AddDate(0, 0, -data.UbiActiveDays)
+	recipiants := []string{}
 	for id, usr := range data.Users {
-		result := apply_wealth_tax_account(data.PersonalAccounts[usr.PersonalAccount], "Personal")
-		for _, org := range usr.Organisations {
-			account := data.OrganisationAccounts[org]
-			if account != data.OrganisationAccounts[treasury] {
-				result += apply_wealth_tax_account(account, data.OrganisationAccounts[org].Name)
-			}
+		if usr.LastActive.After(active_since) {
+			recipiants = append(recipiants, id)
 		}
+	}
+	if len(recipiants) == 0 {
+		return
+	}
 
-		send_embed("Wealth Tax", session, id,
-			fmt.Sprintf("Wealth tax has been applied at `%.2f%%`.\n\n**Payments**\n```%s\n```", data.WealthTax, result),
-			[]*discordgo.MessageEmbedField{})
+	treasury_account := data.OrganisationAccounts[treasury]
+	amount := data.UbiAmount
+	note := ""
+	if treasury_account.Balance < amount*len(recipiants) {
+		amount = treasury_account.Balance / len(recipiants)
+		note = fmt.Sprint("\nThe treasury could not afford the full ", format_cheesecoins(data.UbiAmount), " so the payment has been pro-rated.")
+	}
+	if amount <= 0 {
+		fmt.Println("Treasury cannot afford universal basic income, skipping.")
+		return
+	}
 
-		fmt.Println(result) // Probably send this to the user to notify them of the tax
+	for _, id := range recipiants {
+		account := data.PersonalAccounts[data.Users[id].PersonalAccount]
+		sucsess, _, tax, sales_tax := transaction(amount, treasury_account, account, "Treasury", nil, nil)
+		if !sucsess {
+			continue
+		}
+
+		send_embed("Universal Basic Income", session, id, fmt.Sprint("You have recieved your universal basic income from the treasury.", format_receipt(amount, tax, sales_tax), note), []*discordgo.MessageEmbedField{})
 	}
 }
 
-func check_wealth_tax(session *discordgo.Session) {
+func check_ubi(session *discordgo.Session) {
 	for range time.Tick(time.Minute * 1) {
-		if time.Since(data.LastWealthTax).Hours() > 20 {
-			data.LastWealthTax = data.LastWealthTax.Add(time.Hour * 24)
-			apply_wealth_tax(session)
+		data_mutex.Lock()
+		if data.UbiAmount > 0 && data.UbiInterval > 0 && time.Since(data.LastUbi) >= time.Hour*24*time.Duration(data.UbiInterval) {
+			data.LastUbi = time.Now()
+			apply_ubi(session)
 		}
+		data_mutex.Unlock()
 	}
 }
 
@@ -941,33 +5063,42 @@ func loan_callbacks(session *discordgo.Session) {
 		user := account_owner(acc)
 		for _, loan := range acc.Loans {
 			loan_end := loan.Start.Add(time.Hour * 24 * 7).Unix()
-			banker := account_owner(data.OrganisationAccounts[bank])
+			bankers := org_owners(data.OrganisationAccounts[bank])
 			if !loan.Warning {
 				time.AfterFunc(time.Until(loan.Start.AddDate(0, 0, 5)), func() {
+					data_mutex.Lock()
+					defer data_mutex.Unlock()
+
 					loan.Warning = true
 					send_embed("Loan Due", session, user, fmt.Sprint("Your loan of ", format_cheesecoins(loan.LoanValue), " is due <t:", loan_end, ":R>. ", format_cheesecoins(loan.AmountDue), " is yet to be paid."), []*discordgo.MessageEmbedField{})
 					user_name := data.PersonalAccounts[data.Users[user].PersonalAccount].Name
-					send_embed(fmt.Sprint(user_name, " has a loan due"), session, banker, fmt.Sprint(user_name, " has a loan of ", format_cheesecoins(loan.LoanValue), " which is due <t:", loan_end, ":R>. ", format_cheesecoins(loan.AmountDue), " is yet to be paid."), []*discordgo.MessageEmbedField{})
+					for _, banker := range bankers {
+						send_embed(fmt.Sprint(user_name, " has a loan due"), session, banker, fmt.Sprint(user_name, " has a loan of ", format_cheesecoins(loan.LoanValue), " which is due <t:", loan_end, ":R>. ", format_cheesecoins(loan.AmountDue), " is yet to be paid."), []*discordgo.MessageEmbedField{})
+					}
 
 				})
 			}
 			if !loan.Overdue {
 				time.AfterFunc(time.Until(loan.Start.AddDate(0, 0, 7)), func() {
+					data_mutex.Lock()
+					defer data_mutex.Unlock()
+
 					loan.Overdue = true
 					loan.Warning = true
 					send_embed("Loan Overdue", session, user, fmt.Sprint("Your loan of ", format_cheesecoins(loan.LoanValue), " should have been paid <t:", loan_end, ":R> but ", format_cheesecoins(loan.AmountDue), " is yet to be paid. The bank has been notified and may take legal action."), []*discordgo.MessageEmbedField{})
 					user_name := data.PersonalAccounts[data.Users[user].PersonalAccount].Name
-					send_embed(fmt.Sprint(user_name, " has an overdue loan"), session, banker, fmt.Sprint(user_name, " has a loan of ", format_cheesecoins(loan.LoanValue), " due <t:", loan_end, ":R> but ", format_cheesecoins(loan.AmountDue), " is yet to be paid. Take any legal action you consider necessary."), []*discordgo.MessageEmbedField{})
+					for _, banker := range bankers {
+						send_embed(fmt.Sprint(user_name, " has an overdue loan"), session, banker, fmt.Sprint(user_name, " has a loan of ", format_cheesecoins(loan.LoanValue), " due <t:", loan_end, ":R> but ", format_cheesecoins(loan.AmountDue), " is yet to be paid. Take any legal action you consider necessary."), []*discordgo.MessageEmbedField{})
+					}
 				})
 			}
 		}
 	}
 }
 
-// Called as the first function to run from this module
-func init() {
-	rand.Seed(time.Now().UnixNano())
-
+// Reads the command line and the saved data and starts saving it. Called at the start of main rather than from init,
+// so that tests do not load or overwrite the data file.
+func start() {
 	r, _ := time.Now().MarshalJSON()
 
 	// Parse the bot token as a command line arg from the format `go run . -t [token]`
@@ -979,6 +5110,8 @@ func init() {
 
 	go periodic_save()
 
+	go check_snapshots()
+
 	fmt.Println(string(r), format_cheesecoins(total_currency()))
 }
 
@@ -1018,14 +5151,158 @@ func create_embed(name string, session *discordgo.Session, interaction *discordg
 		Title:     name,
 		Fields:    Fields,
 	}
+	embeds := []*discordgo.MessageEmbed{embed}
+
+	// Discord only allows 25 fields per embed so any extra fields are put in further embeds
+	if len(Fields) > 25 {
+		embed.Fields = Fields[:25]
+		for i := 25; i < len(Fields); i += 25 {
+			end := i + 25
+			if end > len(Fields) {
+				end = len(Fields)
+			}
+			embeds = append(embeds, &discordgo.MessageEmbed{Color: 0xFFE41E, Fields: Fields[i:end]})
+		}
+	}
+
+	// Discord only allows 6000 characters across the embeds of a message so any further embeds are sent as follow up messages
+	messages := [][]*discordgo.MessageEmbed{{}}
+	length := 0
+	for _, e := range embeds {
+		if embed_length(e)+length > 6000 {
+			messages = append(messages, []*discordgo.MessageEmbed{})
+			length = 0
+		}
+		messages[len(messages)-1] = append(messages[len(messages)-1], e)
+		length += embed_length(e)
+	}
 
 	// Send the embed as a response to the provided interaction
+	session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseChannelMessageWithSource, Data: &discordgo.InteractionResponseData{
+		Embeds: messages[0],
+	}})
+	for _, message := range messages[1:] {
+		session.FollowupMessageCreate(session.State.User.ID, interaction.Interaction, true, &discordgo.WebhookParams{Embeds: message})
+	}
+}
+
+// The number of characters in an embed which count towards Discord's limit
+func embed_length(embed *discordgo.MessageEmbed) int {
+	length := utf8.RuneCountInString(embed.Title) + utf8.RuneCountInString(embed.Description)
+	for _, field := range embed.Fields {
+		length += utf8.RuneCountInString(field.Name) + utf8.RuneCountInString(field.Value)
+	}
+	return length
+}
+
+// Utility function to create an embed with a row of buttons in response to an interaction.
+// Pressing a button calls the handler in `componentHandlers` named by the part of its CustomID before the colon.
+func create_buttons(name string, session *discordgo.Session, interaction *discordgo.InteractionCreate, description string, buttons []discordgo.MessageComponent) {
+	embed := &discordgo.MessageEmbed{
+		Author:      &discordgo.MessageEmbedAuthor{},
+		Color:       0xFFE41E,
+		Description: description,
+
+		Timestamp: time.Now().Format(time.RFC3339), // Discord wants ISO8601; RFC3339 is an extension of ISO8601 and should be completely compatible.
+		Title:     name,
+	}
+
+	session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseChannelMessageWithSource, Data: &discordgo.InteractionResponseData{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: []discordgo.MessageComponent{discordgo.ActionsRow{Components: buttons}},
+	}})
+}
+
+// Utility function to create an embed with an attached PNG image in response to an interaction
+func create_image_embed(name string, session *discordgo.Session, interaction *discordgo.InteractionCreate, description string, image io.Reader) {
+	embed := &discordgo.MessageEmbed{
+		Author:      &discordgo.MessageEmbedAuthor{},
+		Color:       0xFFE41E,
+		Description: description,
+		Image:       &discordgo.MessageEmbedImage{URL: "attachment://chart.png"},
+
+		Timestamp: time.Now().Format(time.RFC3339), // Discord wants ISO8601; RFC3339 is an extension of ISO8601 and should be completely compatible.
+		Title:     name,
+	}
+
+	// Send the embed and the image as a response to the provided interaction
 	session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseChannelMessageWithSource, Data: &discordgo.InteractionResponseData{
 		Embeds: []*discordgo.MessageEmbed{embed},
+		Files:  []*discordgo.File{{Name: "chart.png", ContentType: "image/png", Reader: image}},
 	}})
 }
 
-// Utility function to create an embed in response to an interaction
+// Utility function to replace the message containing the buttons that were pressed with an embed
+func update_embed(name string, session *discordgo.Session, interaction *discordgo.InteractionCreate, description string) {
+	embed := &discordgo.MessageEmbed{
+		Author:      &discordgo.MessageEmbedAuthor{},
+		Color:       0xFFE41E,
+		Description: description,
+
+		Timestamp: time.Now().Format(time.RFC3339), // Discord wants ISO8601; RFC3339 is an extension of ISO8601 and should be completely compatible.
+		Title:     name,
+	}
+
+	// Update the message, removing the buttons so they cannot be pressed again
+	session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseUpdateMessage, Data: &discordgo.InteractionResponseData{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: []discordgo.MessageComponent{},
+	}})
+}
+
+// Utility function to reply to a button press with a message only the user who pressed it can see, leaving the message with the buttons as it was
+func ephemeral_embed(name string, session *discordgo.Session, interaction *discordgo.InteractionCreate, description string) {
+	embed := &discordgo.MessageEmbed{
+		Author:      &discordgo.MessageEmbedAuthor{},
+		Color:       0xFFE41E,
+		Description: description,
+
+		Timestamp: time.Now().Format(time.RFC3339), // Discord wants ISO8601; RFC3339 is an extension of ISO8601 and should be completely compatible.
+		Title:     name,
+	}
+
+	session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseChannelMessageWithSource, Data: &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		Flags:  1 << 6, // Ephemeral
+	}})
+}
+
+// Utility function to update the message with the buttons that were pressed, replacing its buttons
+func update_buttons(name string, session *discordgo.Session, interaction *discordgo.InteractionCreate, description string, buttons []discordgo.MessageComponent) {
+	embed := &discordgo.MessageEmbed{
+		Author:      &discordgo.MessageEmbedAuthor{},
+		Color:       0xFFE41E,
+		Description: description,
+
+		Timestamp: time.Now().Format(time.RFC3339), // Discord wants ISO8601; RFC3339 is an extension of ISO8601 and should be completely compatible.
+		Title:     name,
+	}
+
+	session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseUpdateMessage, Data: &discordgo.InteractionResponseData{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: []discordgo.MessageComponent{discordgo.ActionsRow{Components: buttons}},
+	}})
+}
+
+// Utility function to send an embed with a row of buttons to a user.
+// Pressing a button calls the handler in `componentHandlers` named by the part of its CustomID before the colon.
+func send_buttons(name string, session *discordgo.Session, user string, description string, buttons []discordgo.MessageComponent) {
+	embed := &discordgo.MessageEmbed{
+		Author:      &discordgo.MessageEmbedAuthor{},
+		Color:       0xFFE41E,
+		Description: description,
+
+		Timestamp: time.Now().Format(time.RFC3339), // Discord wants ISO8601; RFC3339 is an extension of ISO8601 and should be completely compatible.
+		Title:     name,
+	}
+
+	queue_message(user, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: []discordgo.MessageComponent{discordgo.ActionsRow{Components: buttons}},
+	})
+}
+
+// Utility function to send an embed to a user. Like send_buttons, the message is queued and sent once data_mutex is no longer needed.
 func send_embed(name string, session *discordgo.Session, user string, description string, Fields []*discordgo.MessageEmbedField) {
 	embed := &discordgo.MessageEmbed{
 		Author:      &discordgo.MessageEmbedAuthor{},
@@ -1037,16 +5314,70 @@ func send_embed(name string, session *discordgo.Session, user string, descriptio
 		Fields:    Fields,
 	}
 
-	// Send the embed as a response to the provided interaction
-	channel, err := session.UserChannelCreate(user)
+	queue_message(user, &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}})
+}
 
-	if err != nil {
-		fmt.Println(err)
-	} else {
-		session.ChannelMessageSendEmbed(channel.ID, embed)
+// A direct message waiting to be sent to a user
+type QueuedMessage struct {
+	User    string
+	Message *discordgo.MessageSend
+}
+
+// Queues a direct message to be sent by send_queued_messages. Messages are sent in the order they are queued.
+func queue_message(user string, message *discordgo.MessageSend) {
+	message_queue_mutex.Lock()
+	message_queue = append(message_queue, QueuedMessage{User: user, Message: message})
+	message_queue_mutex.Unlock()
+
+	select {
+	case message_queued <- true:
+	default:
+	}
+}
+
+// Sends direct messages as they are queued. This runs on its own so that slow requests to discord,
+// such as a message to every user, never hold data_mutex and delay commands.
+func send_queued_messages(session *discordgo.Session) {
+	for range message_queued {
+		flush_messages(session)
 	}
 }
 
+// Sends every queued direct message
+func flush_messages(session *discordgo.Session) {
+	message_queue_mutex.Lock()
+	messages := message_queue
+	message_queue = nil
+	message_queue_mutex.Unlock()
+
+	for _, message := range messages {
+		channel, err := session.UserChannelCreate(message.User)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		session.ChannelMessageSendComplex(channel.ID, message.Message)
+	}
+}
+
+// Utility function for providing the personal account name of a user from their discord id
+func format_user(user string) string {
+	cheese_user, ok := data.Users[user]
+	if !ok {
+		return "unknown user"
+	}
+	return data.PersonalAccounts[cheese_user.PersonalAccount].Name
+}
+
+// Utility function for providing an itemised receipt of a payment and the taxes deducted from it
+func format_receipt(amount int, tax int, sales_tax int) string {
+	result := fmt.Sprint("\n```\nAmount Payed    ", format_cheesecoins(amount), "\nTax           - ", format_cheesecoins(tax))
+	if sales_tax > 0 {
+		result += fmt.Sprint("\nSales Tax     - ", format_cheesecoins(sales_tax))
+	}
+	return result + fmt.Sprint("\nRecieved      = ", format_cheesecoins(amount-tax-sales_tax), "\n```")
+}
+
 // Utility function for providing a string of an account
 func format_account(account *Account) string {
 	return fmt.Sprintf("%-20s %s\n", account.Name+":", format_cheesecoins(account.Balance))
@@ -1061,52 +5392,194 @@ func total_currency() int {
 	for _, a := range data.OrganisationAccounts {
 		total += a.Balance
 	}
-	return total
+	return total + data.Escrow.Balance
 }
 
-// Untility function to check if the user has control of the organisation specified.
-// Go does not have an array contains element function.
-func user_has_org(user *discordgo.User, org string, delete_if_found bool) bool {
-	for index, i := range data.Users[user.ID].Organisations {
-		if i == org {
-			if delete_if_found {
-				data.Users[user.ID].Organisations = append(data.Users[user.ID].Organisations[:index], data.Users[user.ID].Organisations[index+1:]...)
+// Untility function to check if the user is a member of the organisation specified with at least the specified role.
+func user_has_org(user *discordgo.User, org string, role int) bool {
+	account, ok := data.OrganisationAccounts[org]
+	if !ok {
+		return false
+	}
+	member_role, ok := account.Members[user.ID]
+	return ok && member_role >= role
+}
 
-			}
-			return true
+// Adds a user to an organisation, or changes their role if they are already a member.
+func add_org_member(user string, org string, role int) {
+	if _, ok := data.OrganisationAccounts[org].Members[user]; !ok {
+		data.Users[user].Organisations = append(data.Users[user].Organisations, org)
+	}
+	data.OrganisationAccounts[org].Members[user] = role
+}
+
+// Removes a user from an organisation.
+func remove_org_member(user string, org string) {
+	delete(data.OrganisationAccounts[org].Members, user)
+	delete(data.OrganisationAccounts[org].Limits, user)
+
+	organisations := []string{}
+	for _, i := range data.Users[user].Organisations {
+		if i != org {
+			organisations = append(organisations, i)
 		}
 	}
-	return false
+	data.Users[user].Organisations = organisations
+}
+
+// Checks that an organisation can be deleted by a user without erasing debts or the stakes of other people.
+// Returns an error string, which is empty if the organisation can be deleted.
+func check_org_deletion(organisation string, user string) string {
+	account := data.OrganisationAccounts[organisation]
+
+	switch organisation {
+	case treasury:
+		return "**ERROR:** You cannot delete the treasury!"
+	case bank:
+		return "**ERROR:** You cannot delete the bank!"
+	case casino:
+		return "**ERROR:** You cannot delete the casino! It is to important."
+	}
+
+	if len(account.Loans) > 0 {
+		owed := 0
+		for _, loan := range account.Loans {
+			owed += loan.AmountDue
+		}
+		return fmt.Sprint("**ERROR:** ", account.Name, " has ", len(account.Loans), " loan(s) outstanding totalling ", format_cheesecoins(owed), ". Repay them before deleting the organisation.")
+	}
+	for _, owner := range org_owners(account) {
+		if owner != user {
+			return fmt.Sprint("**ERROR:** ", account.Name, " has other owners. Remove them with /org_remove_member before deleting the organisation.")
+		}
+	}
+	for holder := range account.Shares {
+		if holder != user {
+			return fmt.Sprint("**ERROR:** Other people hold shares in ", account.Name, ". Buy them back before deleting the organisation.")
+		}
+	}
+	for _, order := range data.Orders {
+		if order.Organisation == organisation && order.User != user && !order.Buy {
+			return fmt.Sprint("**ERROR:** Other people are selling shares in ", account.Name, ". They must cancel their orders before the organisation can be deleted.")
+		}
+	}
+	for _, campaign := range data.Campaigns {
+		if campaign.Organisation == organisation {
+			return fmt.Sprint("**ERROR:** ", account.Name, " is running the campaign ", campaign.Title, ". Cancel it before deleting the organisation.")
+		}
+	}
+	for id, escrow := range data.EscrowPayments {
+		if escrow.Payer == organisation || escrow.Recipiant == organisation {
+			return fmt.Sprint("**ERROR:** ", account.Name, " is taking part in escrow payment #", id, ". It must be released or refunded before the organisation can be deleted.")
+		}
+	}
+	return ""
+}
+
+// Removes an organisation after cancelling anything outstanding against it.
+// The account is kept in the archive so that it still shows up in history.
+func archive_org(organisation string) {
+	account := data.OrganisationAccounts[organisation]
+
+	for _, order := range data.Orders {
+		if order.Organisation == organisation {
+			cancel_order(order)
+		}
+	}
+	for id, payment := range data.PendingPayments {
+		if payment.Organisation == organisation {
+			delete(data.PendingPayments, id)
+		}
+	}
+	for id, offer := range data.TransferOffers {
+		if offer.Organisation == organisation {
+			delete(data.TransferOffers, id)
+		}
+	}
+	for member := range account.Members {
+		remove_org_member(member, organisation)
+	}
+
+	account.Payroll = map[string]*Employee{}
+	account.Archived = time.Now()
+	data.ArchivedOrganisations[organisation] = account
+	delete(data.OrganisationAccounts, organisation)
 }
 
+// Finds the discord id of the person who owns an account. For organisations this is the first owner in order of discord id.
 func account_owner(account *Account) string {
+	if owners := account_owners(account); len(owners) > 0 {
+		return owners[0]
+	}
+	return ""
+}
+
+// Finds the discord ids of the people who own an account, which should all be sent notices about it.
+// For organisations these are all of the owners in order of discord id.
+func account_owners(account *Account) []string {
 	for id, usr := range data.Users {
 		if data.PersonalAccounts[usr.PersonalAccount] == account {
-			return id
+			return []string{id}
 		}
-		for _, org := range usr.Organisations {
-			if data.OrganisationAccounts[org] == account {
-				return id
-			}
+	}
+	return org_owners(account)
+}
+
+// Utility function to check if an account belongs to an organisation rather than a person
+func is_organisation_account(account *Account) bool {
+	for _, org_account := range data.OrganisationAccounts {
+		if org_account == account {
+			return true
 		}
 	}
-	return ""
+	return false
+}
+
+// Conducts a transaction which is not a sale, such as a payout or a move out of escrow, so only transaction tax is charged.
+// Returns `Sucsess bool`, `error string`, `tax int` and `sales tax int`
+func transaction(amount int, payer_account *Account, recipiant_account *Account, payer_name string, session *discordgo.Session, interaction *discordgo.InteractionCreate) (bool, string, int, int) {
+	return make_transaction(amount, payer_account, recipiant_account, payer_name, session, interaction, data.TransactionTax, false)
+}
+
+// Conducts a payment made by a user, which is charged sales tax if it is to an organisation.
+// Returns `Sucsess bool`, `error string`, `tax int` and `sales tax int`
+func sales_transaction(amount int, payer_account *Account, recipiant_account *Account, payer_name string, session *discordgo.Session, interaction *discordgo.InteractionCreate) (bool, string, int, int) {
+	return make_transaction(amount, payer_account, recipiant_account, payer_name, session, interaction, data.TransactionTax, true)
+}
+
+// Conducts a payment of winnings, which is charged the winnings tax instead of transaction tax.
+// Returns `Sucsess bool`, `error string`, `tax int` and `sales tax int`
+func winnings_transaction(amount int, payer_account *Account, recipiant_account *Account, payer_name string, session *discordgo.Session, interaction *discordgo.InteractionCreate) (bool, string, int, int) {
+	return make_transaction(amount, payer_account, recipiant_account, payer_name, session, interaction, data.WinningsTax, false)
 }
 
-// Conducts a transaction. Returns `Sucsess bool`, `error string` and `tax int`
-func transaction(amount int, payer_account *Account, recipiant_account *Account, payer_name string, session *discordgo.Session, interaction *discordgo.InteractionCreate) (bool, string, int) {
+// Conducts a transaction taxed at tax_rate%, also charging sales tax if it is a sale. Use transaction, sales_transaction or winnings_transaction rather than calling this directly.
+func make_transaction(amount int, payer_account *Account, recipiant_account *Account, payer_name string, session *discordgo.Session, interaction *discordgo.InteractionCreate, tax_rate float64, sale bool) (bool, string, int, int) {
 	// Check for negatives
 	if amount < 0 {
-		return false, "**ERROR:** Cannot pay negative cheesecoins", 0
+		return false, "**ERROR:** Cannot pay negative cheesecoins", 0, 0
 	}
 
 	// Check for paying too much
 	if payer_account.Balance < amount {
-		return false, fmt.Sprint("**ERROR:** ", payer_name, " has only ", format_cheesecoins(payer_account.Balance)), 0
+		return false, fmt.Sprint("**ERROR:** ", payer_name, " has only ", format_cheesecoins(payer_account.Balance)), 0, 0
 	}
 
 	// Calculate tax
-	tax := int(math.Ceil(float64(amount) * data.TransactionTax / 100))
+	tax := int(math.Ceil(float64(amount) * tax_rate / 100))
+
+	// Sales tax is charged on sales to organisations (other than the treasury, which recieves the tax anyway, and the bank, where payments repay loans).
+	// The combined tax is rounded up once rather than each tax separately, so that the two cannot round up to more than the amount.
+	sales_tax := 0
+	if sale && recipiant_account != data.OrganisationAccounts[treasury] && recipiant_account != data.OrganisationAccounts[bank] && is_organisation_account(recipiant_account) {
+		sales_tax = int(math.Ceil(float64(amount)*(tax_rate+data.SalesTax)/100)) - tax
+	}
+	if tax > amount {
+		tax = amount
+	}
+	if tax+sales_tax > amount {
+		sales_tax = amount - tax
+	}
 
 	// Handle paying back a loan
 	loan_text := ""
@@ -1115,7 +5588,7 @@ func transaction(amount int, payer_account *Account, recipiant_account *Account,
 		if len(payer_account.Loans) > 0 {
 			fmt.Println("Paying loan.")
 			loan_text += "\n\n**Loan contributions**:"
-			amount_left := amount - tax
+			amount_left := amount - tax - sales_tax
 			for {
 				if amount_left >= payer_account.Loans[0].AmountDue {
 					loan_text += fmt.Sprint("\n", format_cheesecoins(payer_account.Loans[0].AmountDue), " payed off a loan of ", format_cheesecoins(payer_account.Loans[0].LoanValue), " from <t:", payer_account.Loans[0].Start.Unix(), ":f>")
@@ -1138,18 +5611,21 @@ func transaction(amount int, payer_account *Account, recipiant_account *Account,
 	fmt.Println("loan text", loan_text)
 
 	payer_account.Balance -= amount
-	recipiant_account.Balance += amount - tax
-	data.OrganisationAccounts[treasury].Balance += tax
+	recipiant_account.Balance += amount - tax - sales_tax
+	data.OrganisationAccounts[treasury].Balance += tax + sales_tax
+	data.DayTaxCollected += tax + sales_tax
+	data.DayTransactionVolume += amount
+	data.DayTransactions += 1
 
 	if session != nil {
-		recipiant_id := account_owner(recipiant_account)
-
 		text := fmt.Sprint("You've recieved ", format_cheesecoins(amount), " from ", payer_name, " to ", recipiant_account.Name,
-			".\n```\nAmount Payed    ", format_cheesecoins(amount), "\nTax           - ", format_cheesecoins(tax), "\nRecieved      = ", format_cheesecoins(amount-tax), "\n```")
+			".", format_receipt(amount, tax, sales_tax))
 		if interaction == nil {
-			send_embed("Payment", session, recipiant_id,
-				text,
-				[]*discordgo.MessageEmbedField{})
+			for _, recipiant_id := range account_owners(recipiant_account) {
+				send_embed("Payment", session, recipiant_id,
+					text,
+					[]*discordgo.MessageEmbedField{})
+			}
 		} else {
 			create_embed("Payment", session, interaction,
 				text,
@@ -1157,7 +5633,7 @@ func transaction(amount int, payer_account *Account, recipiant_account *Account,
 		}
 	}
 
-	return true, loan_text, tax
+	return true, loan_text, tax, sales_tax
 }
 
 type option_choice []*discordgo.ApplicationCommandOptionChoice
@@ -1173,11 +5649,26 @@ func (employ option_choice) Len() int {
 // This function will be called (due to AddHandler above) every time a new
 // interaction is created.
 func interactionCreate(session *discordgo.Session, interaction *discordgo.InteractionCreate) {
-	interaction_data := interaction.ApplicationCommandData()
+	data_mutex.Lock()
+	defer data_mutex.Unlock()
 
 	user := interaction.User
 	check_new_user(user)
 
+	// Buttons have a custom id of the form `handler:id`
+	if interaction.Type == discordgo.InteractionMessageComponent {
+		component_data := interaction.MessageComponentData()
+		fmt.Println("component", component_data.CustomID, "From ", user.Username)
+
+		parts := strings.SplitN(component_data.CustomID, ":", 2)
+		if handler, ok := componentHandlers[parts[0]]; ok && len(parts) == 2 {
+			handler(HandlerData{session: session, interaction: interaction, component_data: component_data, user: user}, parts[1])
+		}
+		return
+	}
+
+	interaction_data := interaction.ApplicationCommandData()
+
 	// Ignore all messages created by the bot itself
 	// This isn't required in this specific example but it's a good practice.
 	if interaction_data.TargetID == session.State.User.ID {
@@ -1201,6 +5692,13 @@ func interactionCreate(session *discordgo.Session, interaction *discordgo.Intera
 	case discordgo.InteractionApplicationCommand:
 		fmt.Println("interaction", interaction_data.Name, "interaction", interaction, "From ", user.Username)
 
+		data.Users[user.ID].LastActive = time.Now()
+
+		if err := validate_options(command_options(interaction_data)); err != "" {
+			create_embed("Invalid Input", handler_data.session, handler_data.interaction, err, []*discordgo.MessageEmbedField{})
+			return
+		}
+
 		if interaction_data.Name != "sudo_set_bank_holiday" && interaction_data.Name != "bank_holidays" {
 			for _, t := range data.BankHolidays {
 				if day_is_date(t, time.Now()) {
@@ -1211,9 +5709,10 @@ func interactionCreate(session *discordgo.Session, interaction *discordgo.Intera
 		}
 		commandHandlers[interaction_data.Name](handler_data)
 	case discordgo.InteractionApplicationCommandAutocomplete:
+		command, options := command_options(interaction_data)
 		focused := 0
 		for {
-			if interaction_data.Options[focused].Focused {
+			if options[focused].Focused {
 				break
 			}
 			focused += 1
@@ -1221,7 +5720,7 @@ func interactionCreate(session *discordgo.Session, interaction *discordgo.Intera
 
 		values := option_choice{}
 
-		switch commandAutocomplete[interaction_data.Name][focused] {
+		switch commandAutocomplete[command][focused] {
 		case AutoCompleteNone:
 			return
 		case AutoCompleteAllAccounts:
@@ -1232,7 +5731,7 @@ func interactionCreate(session *discordgo.Session, interaction *discordgo.Intera
 				index++
 			}
 			for id, account := range data.OrganisationAccounts {
-				values[index] = &discordgo.ApplicationCommandOptionChoice{Name: account.Name + " (Organisation)", Value: id}
+				values[index] = &discordgo.ApplicationCommandOptionChoice{Name: truncate(account.Name, MaxOrgNameLength) + " (Organisation)", Value: id}
 				index++
 			}
 
@@ -1240,9 +5739,30 @@ func interactionCreate(session *discordgo.Session, interaction *discordgo.Intera
 			values = make(option_choice, len(data.Users[user.ID].Organisations))
 			index := 0
 			for _, org := range data.Users[user.ID].Organisations {
-				values[index] = &discordgo.ApplicationCommandOptionChoice{Name: data.OrganisationAccounts[org].Name + " (Organisation)", Value: org}
+				values[index] = &discordgo.ApplicationCommandOptionChoice{Name: truncate(data.OrganisationAccounts[org].Name, MaxOrgNameLength) + " (Organisation)", Value: org}
 				index++
 			}
+		case AutoCompleteHeldShares:
+			for id, account := range data.OrganisationAccounts {
+				if held_shares(account, user.ID) > 0 {
+					values = append(values, &discordgo.ApplicationCommandOptionChoice{Name: truncate(account.Name, MaxOrgNameLength) + " (Organisation)", Value: id})
+				}
+			}
+		case AutoCompleteAllOrgs:
+			for id, account := range data.OrganisationAccounts {
+				values = append(values, &discordgo.ApplicationCommandOptionChoice{Name: truncate(account.Name, MaxOrgNameLength) + " (Organisation)", Value: id})
+			}
+		case AutoCompleteEscrows:
+			for id, escrow := range data.EscrowPayments {
+				if escrow_party(user, escrow.Payer) || escrow_party(user, escrow.Recipiant) {
+					recipiant, _ := get_account(escrow.Recipiant)
+					values = append(values, &discordgo.ApplicationCommandOptionChoice{Name: truncate(fmt.Sprint("#", id, " ", format_cheesecoins(escrow.Amount), " to ", recipiant.Name, ": ", escrow.Terms), 100), Value: id})
+				}
+			}
+		case AutoCompleteCampaigns:
+			for id, campaign := range data.Campaigns {
+				values = append(values, &discordgo.ApplicationCommandOptionChoice{Name: truncate(fmt.Sprint(campaign.Title, " (", data.OrganisationAccounts[campaign.Organisation].Name, ")"), 100), Value: id})
+			}
 		case AutoCompleteNonSelfUsers:
 			index := 0
 			values = make(option_choice, len(data.PersonalAccounts)-1)
@@ -1262,7 +5782,7 @@ func interactionCreate(session *discordgo.Session, interaction *discordgo.Intera
 		}
 
 		if len(values) > 0 {
-			matches := fuzzy.FindFrom(interaction_data.Options[focused].Value.(string), values)
+			matches := fuzzy.FindFrom(options[focused].Value.(string), values)
 			results := make(option_choice, len(matches))
 			for i, y := range matches {
 				results[i] = values[y.Index]
@@ -1286,6 +5806,8 @@ func interactionCreate(session *discordgo.Session, interaction *discordgo.Intera
 }
 
 func main() {
+	start()
+
 	// Create a new Discord session using the provided bot token.
 	session, err := discordgo.New("Bot " + Token)
 	if err != nil {
@@ -1296,9 +5818,45 @@ func main() {
 	// Register the interaction func as a callback for InteractionCreate events.
 	session.AddHandler(interactionCreate)
 
+	// Start sending direct messages
+	go send_queued_messages(session)
+
 	// Start checking if wealth tax should be applied
 	go check_wealth_tax(session)
 
+	// Start checking if universal basic income should be paid
+	go check_ubi(session)
+
+	// Start applying scheduled rate changes
+	go check_scheduled_rates(session)
+
+	// Start expiring organisation transfer offers
+	go check_transfer_offers(session)
+
+	// Start expiring payments waiting for approval
+	go check_pending_payments(session)
+
+	// Start paying employees
+	go check_payroll(session)
+
+	// Start standing for players who have left their blackjack games
+	go check_blackjack_games(session)
+
+	// Start drawing the lottery
+	go check_lottery(session)
+
+	// Start sending the daily casino reports
+	go check_casino_digest(session)
+
+	// Start refunding wagers which are not accepted or decided in time
+	go check_wagers(session)
+
+	// Start refunding escrow payments which are not released in time
+	go check_escrow_payments(session)
+
+	// Start settling crowdfunding campaigns which have ended
+	go check_campaigns(session)
+
 	// Messages on late loans
 	loan_callbacks(session)
 
@@ -1324,7 +5882,10 @@ func main() {
 	signal.Notify(stop, os.Interrupt)
 	<-stop
 	fmt.Println("Closing connection")
+	data_mutex.Lock()
 	save_data()
+	// Send any direct messages still waiting
+	flush_messages(session)
 	// Cleanly close down the Discord session.
 	session.Close()
 
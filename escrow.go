@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// The longest terms an escrow payment can have
+const MaxEscrowTerms = 500
+
+// How long a super user has to decide a dispute before the payment is refunded
+const EscrowDisputeTimeout = time.Hour * 24 * 7
+
+// A conditional payment held by the bank in escrow until the payer releases it to the recipiant.
+// It is refunded to the payer at the deadline, unless it has been disputed, in which case a super user decides
+// or it is refunded if they have not decided by the dispute deadline.
+type EscrowPayment struct {
+	Payer           string
+	Recipiant       string
+	Requester       string
+	Amount          int
+	Terms           string
+	Created         time.Time
+	Deadline        time.Time
+	Disputed        bool
+	DisputeReason   string
+	DisputeDeadline time.Time
+}
+
+// If a user can act for an account in an escrow payment, meaning it is their personal account or they are a treasurer or owner of the organisation
+func escrow_party(user *discordgo.User, account string) bool {
+	return data.Users[user.ID].PersonalAccount == account || user_has_org(user, account, RoleTreasurer)
+}
+
+// Finds the name of the payer of an escrow payment, marking personal accounts
+func escrow_payer_name(escrow *EscrowPayment) string {
+	if account, ok := data.PersonalAccounts[escrow.Payer]; ok {
+		return account.Name + " (Personal)"
+	}
+	return data.OrganisationAccounts[escrow.Payer].Name
+}
+
+// Formats an escrow payment
+func format_escrow(id string, escrow *EscrowPayment) string {
+	recipiant, _ := get_account(escrow.Recipiant)
+	result := fmt.Sprint("**#", id, "** ", format_cheesecoins(escrow.Amount), " from ", escrow_payer_name(escrow), " to ", recipiant.Name, " for:\n> ", escrow.Terms)
+	if escrow.Disputed {
+		result += fmt.Sprint("\nDisputed, waiting for a super user to decide by <t:", escrow.DisputeDeadline.Unix(), ":f> or it is refunded: ", escrow.DisputeReason)
+	} else {
+		result += fmt.Sprint("\nRefunded <t:", escrow.Deadline.Unix(), ":R> unless released or disputed.")
+	}
+	return result
+}
+
+// Sends a message about an escrow payment to the user who created it and the owners of the recipiant account
+func notify_escrow(session *discordgo.Session, escrow *EscrowPayment, title string, description string) {
+	recipiant, _ := get_account(escrow.Recipiant)
+	send_embed(title, session, escrow.Requester, description, []*discordgo.MessageEmbedField{})
+	for _, owner := range account_owners(recipiant) {
+		if owner != escrow.Requester {
+			send_embed(title, session, owner, description, []*discordgo.MessageEmbedField{})
+		}
+	}
+}
+
+// Pays an escrow payment to the recipiant, which pays the usual taxes (including sales tax) as if the payer had paid them directly with /pay
+func release_escrow(session *discordgo.Session, id string, reason string) string {
+	escrow := data.EscrowPayments[id]
+	delete(data.EscrowPayments, id)
+
+	recipiant, _ := get_account(escrow.Recipiant)
+	_, _, tax, sales_tax := sales_transaction(escrow.Amount, data.Escrow, recipiant, escrow_payer_name(escrow), nil, nil)
+
+	result := fmt.Sprint(format_cheesecoins(escrow.Amount), " held in escrow #", id, " has been released to ", recipiant.Name, ". ", reason, format_receipt(escrow.Amount, tax, sales_tax))
+	notify_escrow(session, escrow, "Escrow released", result)
+	return result
+}
+
+// Returns an escrow payment to the payer
+func refund_escrow(session *discordgo.Session, id string, reason string) string {
+	escrow := data.EscrowPayments[id]
+	delete(data.EscrowPayments, id)
+
+	payer, _ := get_account(escrow.Payer)
+	data.Escrow.Balance -= escrow.Amount
+	payer.Balance += escrow.Amount
+
+	result := fmt.Sprint(format_cheesecoins(escrow.Amount), " held in escrow #", id, " has been refunded to ", escrow_payer_name(escrow), ". ", reason)
+	notify_escrow(session, escrow, "Escrow refunded", result)
+	return result
+}
+
+// The buttons for a super user to decide a disputed escrow payment
+func escrow_arbiter_buttons(id string) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.Button{Label: "Release to recipiant", Style: discordgo.SuccessButton, CustomID: "escrow_arbitrate_release:" + id},
+		discordgo.Button{Label: "Refund payer", Style: discordgo.DangerButton, CustomID: "escrow_arbitrate_refund:" + id},
+	}
+}
+
+// Check every minute for escrow payments past their deadline, or disputes past theirs, and refund them
+func check_escrow_payments(session *discordgo.Session) {
+	for range time.Tick(time.Minute * 1) {
+		data_mutex.Lock()
+		for id, escrow := range data.EscrowPayments {
+			if !escrow.Disputed && time.Now().After(escrow.Deadline) {
+				refund_escrow(session, id, "It was not released before the deadline.")
+			} else if escrow.Disputed && time.Now().After(escrow.DisputeDeadline) {
+				refund_escrow(session, id, "The dispute was not decided in time.")
+			}
+		}
+		data_mutex.Unlock()
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// An order to buy or sell shares in an organisation. A price of zero is a market order.
+// Cash for limit buy orders and the shares for sell orders are held in escrow while the order is open.
+type Order struct {
+	Id           string
+	Organisation string
+	User         string
+	Buy          bool
+	Price        int
+	Shares       int
+	Time         time.Time
+}
+
+// A trade of shares made when two orders are matched
+type Trade struct {
+	Organisation string
+	Buyer        string
+	Seller       string
+	Shares       int
+	Price        int
+	Time         time.Time
+}
+
+// Finds the open orders for an organisation on one side of the book, best price first and then oldest first
+func order_book(organisation string, buy bool) []*Order {
+	result := []*Order{}
+	for _, order := range data.Orders {
+		if order.Organisation == organisation && order.Buy == buy {
+			result = append(result, order)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Price != result[j].Price {
+			return (result[i].Price > result[j].Price) == buy
+		}
+		return result[i].Time.Before(result[j].Time)
+	})
+	return result
+}
+
+// Finds the best resting order which an incoming order could trade with, or nil if there is none.
+// Users never trade with themselves.
+func best_match(order *Order) *Order {
+	for _, resting := range order_book(order.Organisation, !order.Buy) {
+		if resting.User == order.User {
+			continue
+		}
+		if order.Price != 0 && ((order.Buy && resting.Price > order.Price) || (!order.Buy && resting.Price < order.Price)) {
+			return nil
+		}
+		return resting
+	}
+	return nil
+}
+
+// Removes an order from the book
+func remove_order(id string) {
+	orders := []*Order{}
+	for _, order := range data.Orders {
+		if order.Id != id {
+			orders = append(orders, order)
+		}
+	}
+	data.Orders = orders
+}
+
+// Returns the cash or shares held in escrow for an order to the user who placed it and removes it from the book
+func cancel_order(order *Order) {
+	if order.Buy {
+		refund := order.Shares * order.Price
+		data.Escrow.Balance -= refund
+		data.PersonalAccounts[data.Users[order.User].PersonalAccount].Balance += refund
+	} else {
+		data.OrganisationAccounts[order.Organisation].Shares[order.User] += order.Shares
+	}
+	order.Shares = 0
+	remove_order(order.Id)
+}
+
+// Places an order, holding the cash or shares in escrow, and matches it against the book with price-time priority.
+// Trades are made at the price of the resting order and the seller is paid through a transaction from escrow.
+// Any part of a market order that cannot be filled is cancelled, and any part of a limit order is left on the book.
+// Returns an error string, which is empty if the order was placed, and a description of the trades made.
+func place_order(session *discordgo.Session, order *Order) (string, string) {
+	account := data.OrganisationAccounts[order.Organisation]
+	buyer_account := data.PersonalAccounts[data.Users[order.User].PersonalAccount]
+
+	if order.Buy && order.Price > 0 {
+		if buyer_account.Balance < order.Shares*order.Price {
+			return fmt.Sprint("**ERROR:** You need ", format_cheesecoins(order.Shares*order.Price), " to place this order but have only ", format_cheesecoins(buyer_account.Balance)), ""
+		}
+		buyer_account.Balance -= order.Shares * order.Price
+		data.Escrow.Balance += order.Shares * order.Price
+	} else if !order.Buy {
+		if account.Shares[order.User] < order.Shares {
+			return fmt.Sprint("**ERROR:** You have only ", account.Shares[order.User], " shares in ", account.Name), ""
+		}
+		account.Shares[order.User] -= order.Shares
+		if account.Shares[order.User] == 0 {
+			delete(account.Shares, order.User)
+		}
+	}
+
+	order.Id = fmt.Sprint(data.NextOrder)
+	data.NextOrder += 1
+	order.Time = time.Now()
+
+	result := ""
+	for order.Shares > 0 {
+		resting := best_match(order)
+		if resting == nil {
+			break
+		}
+
+		buy, sell := order, resting
+		if !order.Buy {
+			buy, sell = resting, order
+		}
+		buyer_account := data.PersonalAccounts[data.Users[buy.User].PersonalAccount]
+
+		price := resting.Price
+		shares := order.Shares
+		if resting.Shares < shares {
+			shares = resting.Shares
+		}
+
+		if buy.Price == 0 {
+			// Market buy orders pay for each trade as it happens
+			if buyer_account.Balance < shares*price {
+				shares = buyer_account.Balance / price
+				if shares == 0 {
+					result += "\nYou ran out of cheesecoins."
+					break
+				}
+			}
+			buyer_account.Balance -= shares * price
+			data.Escrow.Balance += shares * price
+		}
+
+		// If the seller cannot be paid no shares change hands, and the orders are left as they were
+		sucsess, err, tax, _ := transaction(shares*price, data.Escrow, data.PersonalAccounts[data.Users[sell.User].PersonalAccount], "Stock Exchange", nil, nil)
+		if !sucsess {
+			if buy.Price == 0 {
+				data.Escrow.Balance -= shares * price
+				buyer_account.Balance += shares * price
+			}
+			result += fmt.Sprint("\n", err, " The trade with ", format_user(resting.User), " could not be made.")
+			break
+		}
+		if buy.Price > price {
+			// The buyer put more in escrow than the trade costs
+			data.Escrow.Balance -= shares * (buy.Price - price)
+			buyer_account.Balance += shares * (buy.Price - price)
+		}
+		account.Shares[buy.User] += shares
+		buy.Shares -= shares
+		sell.Shares -= shares
+
+		data.Trades = append(data.Trades, &Trade{Organisation: order.Organisation, Buyer: buy.User, Seller: sell.User, Shares: shares, Price: price, Time: time.Now()})
+		result += fmt.Sprint("\n", shares, " shares at ", format_cheesecoins(price), " with ", format_user(resting.User))
+
+		verb, proceeds := "bought", ""
+		if !resting.Buy {
+			verb, proceeds = "sold", fmt.Sprint(" You recieved ", format_cheesecoins(shares*price-tax), " after tax.")
+		}
+		send_embed("Order filled", session, resting.User, fmt.Sprint("You have ", verb, " ", shares, " shares in ", account.Name, " at ", format_cheesecoins(price), " each.", proceeds), []*discordgo.MessageEmbedField{})
+
+		if resting.Shares == 0 {
+			remove_order(resting.Id)
+		}
+	}
+
+	if order.Shares > 0 {
+		if order.Price == 0 {
+			if !order.Buy {
+				account.Shares[order.User] += order.Shares
+			}
+			result += fmt.Sprint("\nThe remaining ", order.Shares, " shares could not be traded so the order has been cancelled.")
+		} else {
+			data.Orders = append(data.Orders, order)
+			result += fmt.Sprint("\nThe remaining ", order.Shares, " shares are on the market as order #", order.Id, ".")
+		}
+	}
+
+	return "", result
+}
+
+// Formats the best bid, best ask and last trade price of an organisation's shares
+func format_market(organisation string) string {
+	bid, ask, last := "-", "-", "-"
+	if bids := order_book(organisation, true); len(bids) > 0 {
+		bid = format_cheesecoins(bids[0].Price)
+	}
+	if asks := order_book(organisation, false); len(asks) > 0 {
+		ask = format_cheesecoins(asks[0].Price)
+	}
+	for _, trade := range data.Trades {
+		if trade.Organisation == organisation {
+			last = format_cheesecoins(trade.Price)
+		}
+	}
+	return fmt.Sprintf("\n%-20s %12s %12s %12s", data.OrganisationAccounts[organisation].Name+":", bid, ask, last)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Sets up an exchange with no taxes for shares in "1001", where users a and c each hold 100 shares
+// and a, b and c each have 100 cheesecoins
+func setup_exchange() *Account {
+	organisation_account := &Account{Name: "Cheese Co", Members: map[string]int{}, Shares: map[string]int{"a": 100, "c": 100}}
+	data = Data{
+		Users:                map[string]*User{},
+		PersonalAccounts:     map[string]*Account{},
+		OrganisationAccounts: map[string]*Account{"1000": {Name: "Treasury"}, "1001": organisation_account},
+		Escrow:               &Account{Name: "Escrow"},
+	}
+	treasury, bank = "1000", "1003"
+	for _, user := range []string{"a", "b", "c"} {
+		data.Users[user] = &User{PersonalAccount: user}
+		data.PersonalAccounts[user] = &Account{Name: user, Balance: 10000}
+	}
+	return organisation_account
+}
+
+// Describes the open orders, as the shares left on each user's buy and sell orders
+func open_orders() map[string]int {
+	result := map[string]int{}
+	for _, order := range data.Orders {
+		side := "sell"
+		if order.Buy {
+			side = "buy"
+		}
+		result[order.User+" "+side] += order.Shares
+	}
+	return result
+}
+
+func TestPlaceOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		orders   []Order
+		shares   map[string]int
+		balances map[string]int
+		book     map[string]int
+	}{
+		{
+			name:     "a partly filled limit order stays on the book",
+			orders:   []Order{{User: "a", Price: 100, Shares: 10}, {User: "b", Buy: true, Price: 100, Shares: 4}},
+			shares:   map[string]int{"a": 90, "b": 4, "c": 100},
+			balances: map[string]int{"a": 10400, "b": 9600, "c": 10000},
+			book:     map[string]int{"a sell": 6},
+		},
+		{
+			name:     "a limit buy trades at the resting price and gets the difference back",
+			orders:   []Order{{User: "a", Price: 100, Shares: 10}, {User: "b", Buy: true, Price: 150, Shares: 10}},
+			shares:   map[string]int{"a": 90, "b": 10, "c": 100},
+			balances: map[string]int{"a": 11000, "b": 9000, "c": 10000},
+			book:     map[string]int{},
+		},
+		{
+			name:     "orders at the same price fill oldest first",
+			orders:   []Order{{User: "a", Price: 100, Shares: 5}, {User: "c", Price: 100, Shares: 5}, {User: "b", Buy: true, Price: 100, Shares: 5}},
+			shares:   map[string]int{"a": 95, "b": 5, "c": 95},
+			balances: map[string]int{"a": 10500, "b": 9500, "c": 10000},
+			book:     map[string]int{"c sell": 5},
+		},
+		{
+			name:     "a market buy takes the best prices first and cancels the rest",
+			orders:   []Order{{User: "a", Price: 120, Shares: 5}, {User: "c", Price: 100, Shares: 5}, {User: "b", Buy: true, Shares: 20}},
+			shares:   map[string]int{"a": 95, "b": 10, "c": 95},
+			balances: map[string]int{"a": 10600, "b": 8900, "c": 10500},
+			book:     map[string]int{},
+		},
+		{
+			name:     "a market buy stops when the buyer runs out of cheesecoins",
+			orders:   []Order{{User: "a", Price: 150, Shares: 100}, {User: "b", Buy: true, Shares: 100}},
+			shares:   map[string]int{"a": 0, "b": 66, "c": 100},
+			balances: map[string]int{"a": 19900, "b": 100, "c": 10000},
+			book:     map[string]int{"a sell": 34},
+		},
+		{
+			name:     "a market sell returns the shares it cannot sell",
+			orders:   []Order{{User: "b", Buy: true, Price: 100, Shares: 10}, {User: "a", Shares: 15}},
+			shares:   map[string]int{"a": 90, "b": 10, "c": 100},
+			balances: map[string]int{"a": 11000, "b": 9000, "c": 10000},
+			book:     map[string]int{},
+		},
+		{
+			name:     "users never trade with themselves",
+			orders:   []Order{{User: "a", Price: 100, Shares: 10}, {User: "a", Buy: true, Price: 100, Shares: 5}, {User: "b", Buy: true, Price: 100, Shares: 5}},
+			shares:   map[string]int{"a": 90, "b": 5, "c": 100},
+			balances: map[string]int{"a": 10000, "b": 9500, "c": 10000},
+			book:     map[string]int{"a sell": 5, "a buy": 5},
+		},
+		{
+			name:     "a market order skips the user's own orders",
+			orders:   []Order{{User: "a", Price: 100, Shares: 10}, {User: "c", Price: 200, Shares: 10}, {User: "a", Buy: true, Shares: 5}},
+			shares:   map[string]int{"a": 95, "b": 0, "c": 90},
+			balances: map[string]int{"a": 9000, "b": 10000, "c": 11000},
+			book:     map[string]int{"a sell": 10, "c sell": 5},
+		},
+	}
+
+	for _, test := range tests {
+		organisation_account := setup_exchange()
+		for i := range test.orders {
+			order := test.orders[i]
+			order.Organisation = "1001"
+			if err, _ := place_order(nil, &order); err != "" {
+				t.Fatalf("%s: order %d was not placed: %s", test.name, i, err)
+			}
+		}
+
+		for user, want := range test.shares {
+			if got := organisation_account.Shares[user]; got != want {
+				t.Errorf("%s: %s has %d shares, want %d", test.name, user, got, want)
+			}
+		}
+		for user, want := range test.balances {
+			if got := data.PersonalAccounts[user].Balance; got != want {
+				t.Errorf("%s: %s has %s, want %s", test.name, user, format_cheesecoins(got), format_cheesecoins(want))
+			}
+		}
+		if got := open_orders(); fmt.Sprint(got) != fmt.Sprint(test.book) {
+			t.Errorf("%s: the open orders are %v, want %v", test.name, got, test.book)
+		}
+	}
+}
+
+func TestFailedTradeIsNotMade(t *testing.T) {
+	organisation_account := setup_exchange()
+	if err, _ := place_order(nil, &Order{Organisation: "1001", User: "b", Buy: true, Price: 100, Shares: 10}); err != "" {
+		t.Fatal(err)
+	}
+
+	// The escrow cannot pay the seller, so the trade must fail without giving the buyer any shares
+	data.Escrow.Balance = 0
+	if err, _ := place_order(nil, &Order{Organisation: "1001", User: "a", Price: 100, Shares: 10}); err != "" {
+		t.Fatal(err)
+	}
+
+	if got := organisation_account.Shares["b"]; got != 0 {
+		t.Errorf("b was given %d shares", got)
+	}
+	if got := data.PersonalAccounts["a"].Balance; got != 10000 {
+		t.Errorf("a has %s, want 100.00", format_cheesecoins(got))
+	}
+	if got, want := open_orders(), map[string]int{"a sell": 10, "b buy": 10}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("the open orders are %v, want %v", got, want)
+	}
+}
@@ -2,7 +2,7 @@ module cheeseland/cheesebot
 
 go 1.17
 
-require github.com/bwmarrin/discordgo v0.23.3-0.20211117035633-fd6228c0d536 // direct
+require github.com/bwmarrin/discordgo v0.24.0 // direct
 
 require (
 	github.com/gorilla/websocket v1.4.2 // indirect
@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestSalesTransactionTaxNeverExceedsAmount(t *testing.T) {
+	tests := []struct {
+		amount         int
+		transactionTax float64
+		salesTax       float64
+		wantTax        int
+		wantSalesTax   int
+	}{
+		{amount: 1, transactionTax: 50, salesTax: 50, wantTax: 1, wantSalesTax: 0},
+		{amount: 3, transactionTax: 50, salesTax: 50, wantTax: 2, wantSalesTax: 1},
+		{amount: 101, transactionTax: 10, salesTax: 5, wantTax: 11, wantSalesTax: 5},
+		{amount: 7, transactionTax: 0, salesTax: 100, wantTax: 0, wantSalesTax: 7},
+	}
+
+	for _, test := range tests {
+		payer := &Account{Name: "payer", Balance: test.amount}
+		shop := &Account{Name: "shop"}
+		data = Data{TransactionTax: test.transactionTax, SalesTax: test.salesTax, OrganisationAccounts: map[string]*Account{"1000": {Name: "treasury"}, "1001": shop}}
+		treasury, bank = "1000", "1003"
+
+		sucsess, err, tax, sales_tax := sales_transaction(test.amount, payer, shop, payer.Name, nil, nil)
+		if !sucsess {
+			t.Fatalf("paying %d failed: %s", test.amount, err)
+		}
+		if tax != test.wantTax || sales_tax != test.wantSalesTax {
+			t.Errorf("paying %d at %v%% + %v%% charged %d + %d tax, want %d + %d", test.amount, test.transactionTax, test.salesTax, tax, sales_tax, test.wantTax, test.wantSalesTax)
+		}
+		if shop.Balance < 0 {
+			t.Errorf("paying %d at %v%% + %v%% left the recipiant with %d", test.amount, test.transactionTax, test.salesTax, shop.Balance)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// The key of the casino seeds used to draw the lottery, so that draws can be checked with /casino_verify
+const LotterySeed = "lottery"
+
+// A round of the lottery. The price of the tickets is held in escrow until the draw.
+// The casino cut and lottery tax are fixed when the round starts, so that changing the tax cannot take more than the pool.
+type LotteryRound struct {
+	Id          string
+	TicketPrice int
+	Tickets     map[string]int
+	Winners     int
+	CasinoCut   float64
+	LotteryTax  float64
+	Draw        time.Time
+	Drawn       bool
+	Prizes      map[string]int
+	SeedHash    string
+}
+
+// Finds the round of the lottery which is open for tickets, or nil if there is none
+func open_lottery() *LotteryRound {
+	if len(data.LotteryRounds) == 0 || data.LotteryRounds[len(data.LotteryRounds)-1].Drawn {
+		return nil
+	}
+	return data.LotteryRounds[len(data.LotteryRounds)-1]
+}
+
+// Finds the number of tickets sold in a round of the lottery
+func lottery_tickets(round *LotteryRound) int {
+	total := 0
+	for _, tickets := range round.Tickets {
+		total += tickets
+	}
+	return total
+}
+
+// Starts a new round of the lottery with a fresh server seed, whose hash is published so the draw can be checked afterwards
+func start_lottery(ticket_price int, draw time.Time, winners int, casino_cut float64) *LotteryRound {
+	round := &LotteryRound{Id: fmt.Sprint(len(data.LotteryRounds) + 1), TicketPrice: ticket_price, Tickets: map[string]int{}, Winners: winners, CasinoCut: casino_cut, LotteryTax: data.LotteryTax, Draw: draw}
+	rotate_casino_seed(LotterySeed, "round "+round.Id)
+	round.SeedHash = hash_seed(casino_seed(LotterySeed).ServerSeed)
+	data.LotteryRounds = append(data.LotteryRounds, round)
+	return round
+}
+
+// Draws the winning tickets of the open round, pays the cuts to the casino and treasury and splits the rest of the pool between the winners.
+// Every participant is sent the results, along with the server seed which is revealed by the draw.
+func draw_lottery(session *discordgo.Session) {
+	round := open_lottery()
+	round.Drawn = true
+	round.Prizes = map[string]int{}
+
+	// List every ticket in a fixed order so that the draw can be reproduced
+	users := []string{}
+	for user := range round.Tickets {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+	tickets := []string{}
+	for _, user := range users {
+		for i := 0; i < round.Tickets[user]; i++ {
+			tickets = append(tickets, user)
+		}
+	}
+	if len(tickets) == 0 {
+		rotate_casino_seed(LotterySeed, "")
+		return
+	}
+
+	pool := len(tickets) * round.TicketPrice
+	treasury_cut := int(float64(pool) * round.LotteryTax / 100)
+	casino_cut := int(float64(pool) * round.CasinoCut / 100)
+	winners := round.Winners
+	if winners > len(tickets) {
+		winners = len(tickets)
+	}
+	prize := (pool - treasury_cut - casino_cut) / winners
+
+	// Anything left over from splitting the prize goes to the casino
+	casino_cut = pool - treasury_cut - prize*winners
+
+	results := ""
+	for i := 0; i < winners; i++ {
+		index, nonce := casino_roll(LotterySeed, "Lottery", len(tickets))
+		winner := tickets[index]
+		tickets = append(tickets[:index], tickets[index+1:]...)
+
+		round.Prizes[winner] += prize
+		results += fmt.Sprint("\nRoll #", nonce, ": ", format_user(winner), " wins ", format_cheesecoins(prize))
+	}
+
+	record_casino_bet(LotterySeed, "Lottery", pool, pool-casino_cut, round.CasinoCut/100)
+	transaction(treasury_cut, data.Escrow, data.OrganisationAccounts[treasury], "Lottery", session, nil)
+	transaction(casino_cut, data.Escrow, data.OrganisationAccounts[casino], "Lottery", session, nil)
+	winnings_tax := 0
+	for winner, amount := range round.Prizes {
+		_, _, tax, _ := winnings_transaction(amount, data.Escrow, data.PersonalAccounts[data.Users[winner].PersonalAccount], "Lottery", session, nil)
+		winnings_tax += tax
+		record_gambling_result(winner, amount)
+	}
+	if winnings_tax > 0 {
+		results += fmt.Sprint("\nThe winners paid ", format_cheesecoins(winnings_tax), " in winnings tax.")
+	}
+
+	old := rotate_casino_seed(LotterySeed, "")
+	description := fmt.Sprint("Round ", round.Id, " of the lottery has been drawn. ", lottery_tickets(round), " tickets were sold for a pool of ", format_cheesecoins(pool), ", of which ",
+		format_cheesecoins(treasury_cut), " went to the treasury and ", format_cheesecoins(casino_cut), " to the casino.\n", results,
+		"\n\nThe server seed was `", old.ServerSeed, "` with the client seed `", old.ClientSeed, "`. Tickets are numbered in order of discord id, and each winning ticket is removed before the next roll.")
+	for _, user := range users {
+		send_embed("Lottery results", session, user, description, []*discordgo.MessageEmbedField{})
+	}
+	for _, owner := range org_owners(data.OrganisationAccounts[casino]) {
+		if round.Tickets[owner] == 0 {
+			send_embed("Lottery results", session, owner, description, []*discordgo.MessageEmbedField{})
+		}
+	}
+}
+
+// Check every minute if the lottery should be drawn
+func check_lottery(session *discordgo.Session) {
+	for range time.Tick(time.Minute * 1) {
+		data_mutex.Lock()
+		if round := open_lottery(); round != nil && time.Now().After(round.Draw) {
+			draw_lottery(session)
+		}
+		data_mutex.Unlock()
+	}
+}
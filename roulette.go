@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The most bets which can be placed on one spin of the roulette wheel
+const MaxRouletteBets = 10
+
+// A bet on one spin of a single zero roulette wheel.
+// Kind is one of straight, red, black, odd, even, dozen or column and Number is the number, dozen or column bet on.
+type RouletteBet struct {
+	Kind   string
+	Number int
+	Stake  int
+}
+
+var red_numbers = map[int]bool{1: true, 3: true, 5: true, 7: true, 9: true, 12: true, 14: true, 16: true, 18: true, 19: true, 21: true, 23: true, 25: true, 27: true, 30: true, 32: true, 34: true, 36: true}
+
+// Parses bets of the form "red 10, 17 2.5, dozen1 5" into roulette bets.
+// Returns the bets and an error string, which is empty if the bets are valid.
+func parse_roulette_bets(text string) ([]*RouletteBet, string) {
+	bets := []*RouletteBet{}
+	for _, part := range strings.Split(text, ",") {
+		fields := strings.Fields(strings.ToLower(part))
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Sprint("**ERROR:** `", strings.TrimSpace(part), "` should be a bet followed by an amount, such as `red 10`")
+		}
+
+		// Checked the same way as cheesecoin options, written so that NaN fails every comparison
+		amount, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil || !(amount >= 0.01 && amount <= MaxCheesecoinInput) {
+			return nil, fmt.Sprint("**ERROR:** `", fields[1], "` is not a valid amount of cheesecoins")
+		}
+		if math.Abs(amount*100-math.Round(amount*100)) > 1e-6 {
+			return nil, fmt.Sprint("**ERROR:** `", fields[1], "` cannot have more than 2 decimal places")
+		}
+		bet := &RouletteBet{Stake: int(math.Round(amount * 100))}
+		if bet.Stake < 1 || bet.Stake > MaxCheesecoinInput*100 {
+			return nil, fmt.Sprint("**ERROR:** `", fields[1], "` is not a valid amount of cheesecoins")
+		}
+
+		name := fields[0]
+		switch {
+		case name == "red" || name == "black" || name == "odd" || name == "even":
+			bet.Kind = name
+		case strings.HasPrefix(name, "dozen") || strings.HasPrefix(name, "column"):
+			bet.Kind = strings.TrimRight(name, "0123456789")
+			bet.Number, err = strconv.Atoi(strings.TrimPrefix(name, bet.Kind))
+			if err != nil || bet.Number < 1 || bet.Number > 3 {
+				return nil, fmt.Sprint("**ERROR:** `", name, "` should be numbered 1 to 3, such as `", bet.Kind, "1`")
+			}
+		default:
+			bet.Kind = "straight"
+			bet.Number, err = strconv.Atoi(name)
+			if err != nil || bet.Number < 0 || bet.Number > 36 {
+				return nil, fmt.Sprint("**ERROR:** `", name, "` is not a bet. Use a number from 0 to 36, red, black, odd, even, dozen1-3 or column1-3")
+			}
+		}
+		bets = append(bets, bet)
+	}
+
+	if len(bets) == 0 {
+		return nil, "**ERROR:** You must place at least one bet"
+	}
+	if len(bets) > MaxRouletteBets {
+		return nil, fmt.Sprint("**ERROR:** You can place at most ", MaxRouletteBets, " bets on one spin")
+	}
+	return bets, ""
+}
+
+// If a bet wins when the ball lands on a number. Zero loses every bet except a straight bet on zero.
+func roulette_wins(bet *RouletteBet, number int) bool {
+	if bet.Kind == "straight" {
+		return number == bet.Number
+	}
+	if number == 0 {
+		return false
+	}
+	switch bet.Kind {
+	case "red":
+		return red_numbers[number]
+	case "black":
+		return !red_numbers[number]
+	case "odd":
+		return number%2 == 1
+	case "even":
+		return number%2 == 0
+	case "dozen":
+		return (number-1)/12+1 == bet.Number
+	case "column":
+		return (number-1)%3+1 == bet.Number
+	}
+	return false
+}
+
+// The winnings on a bet if it wins, which are the standard odds reduced by the house edge
+func roulette_winnings(bet *RouletteBet) int {
+	odds := 1
+	switch bet.Kind {
+	case "straight":
+		odds = 35
+	case "dozen", "column":
+		odds = 2
+	}
+	return int(float64(bet.Stake*odds) * (100 - data.RouletteHouseEdge) / 100)
+}
+
+// Finds how much the casino pays the player overall (negative if the player loses) if the ball lands on a number
+func roulette_payout(bets []*RouletteBet, number int) int {
+	payout := 0
+	for _, bet := range bets {
+		if roulette_wins(bet, number) {
+			payout += roulette_winnings(bet)
+		} else {
+			payout -= bet.Stake
+		}
+	}
+	return payout
+}
+
+// The most that the casino could have to pay on a spin
+func roulette_worst_case(bets []*RouletteBet) int {
+	worst := 0
+	for number := 0; number <= 36; number++ {
+		if payout := roulette_payout(bets, number); payout > worst {
+			worst = payout
+		}
+	}
+	return worst
+}
+
+// Formats a bet, such as "Dozen 2"
+func format_roulette_bet(bet *RouletteBet) string {
+	switch bet.Kind {
+	case "straight":
+		return fmt.Sprint("Number ", bet.Number)
+	case "dozen", "column":
+		return fmt.Sprint(strings.Title(bet.Kind), " ", bet.Number)
+	}
+	return strings.Title(bet.Kind)
+}
+
+// Formats a roulette number with its colour
+func format_roulette_number(number int) string {
+	if number == 0 {
+		return "🟩0"
+	}
+	if red_numbers[number] {
+		return fmt.Sprint("🟥", number)
+	}
+	return fmt.Sprint("⬛", number)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// How long the counterparty has to accept a wager
+const WagerOfferTimeout = time.Hour * 24
+
+// The longest terms a wager can have
+const MaxWagerTerms = 500
+
+// A bet between two members. Both stakes are held in escrow until the parties agree on the winner,
+// the arbiter picks the winner or the deadline passes and the stakes are refunded.
+type Wager struct {
+	Proposer     string
+	Counterparty string
+	Arbiter      string
+	Amount       int
+	Terms        string
+	Days         int
+	Accepted     bool
+	Expires      time.Time
+	Deadline     time.Time
+	Votes        map[string]string
+	Disputed     bool
+}
+
+// Formats a wager between two members
+func format_wager(wager *Wager) string {
+	result := fmt.Sprint(format_user(wager.Proposer), " and ", format_user(wager.Counterparty), " have each staked ", format_cheesecoins(wager.Amount), " on:\n> ", wager.Terms)
+	if wager.Arbiter != "" {
+		result += fmt.Sprint("\nDisagreements are settled by ", format_user(wager.Arbiter), ".")
+	}
+	return result
+}
+
+// The buttons to pick the winner of a wager, using the handler for a party's vote or the arbiter's decision
+func wager_winner_buttons(handler string, id string) []discordgo.MessageComponent {
+	wager := data.Wagers[id]
+	return []discordgo.MessageComponent{
+		discordgo.Button{Label: truncate(data.PersonalAccounts[data.Users[wager.Proposer].PersonalAccount].Name, 70) + " won", Style: discordgo.PrimaryButton, CustomID: handler + ":" + id + "/" + wager.Proposer},
+		discordgo.Button{Label: truncate(data.PersonalAccounts[data.Users[wager.Counterparty].PersonalAccount].Name, 70) + " won", Style: discordgo.PrimaryButton, CustomID: handler + ":" + id + "/" + wager.Counterparty},
+	}
+}
+
+// Splits the id of a wager button into the id of the wager and the winner it picks
+func split_wager_winner(id string) (string, string) {
+	parts := strings.SplitN(id, "/", 2)
+	if len(parts) != 2 {
+		return id, ""
+	}
+	return parts[0], parts[1]
+}
+
+// Pays both stakes of a wager to the winner. The loser's stake is a payment from the loser, so it pays transaction tax.
+func settle_wager(session *discordgo.Session, id string, winner string) {
+	wager := data.Wagers[id]
+	delete(data.Wagers, id)
+
+	loser := wager.Proposer
+	if winner == wager.Proposer {
+		loser = wager.Counterparty
+	}
+	winner_account := data.PersonalAccounts[data.Users[winner].PersonalAccount]
+	loser_account := data.PersonalAccounts[data.Users[loser].PersonalAccount]
+
+	data.Escrow.Balance -= wager.Amount
+	winner_account.Balance += wager.Amount
+	_, _, tax, _ := transaction(wager.Amount, data.Escrow, winner_account, loser_account.Name, nil, nil)
+
+	result := fmt.Sprint(format_wager(wager), "\n\n", format_user(winner), " has won and been paid ", format_cheesecoins(wager.Amount*2-tax), " (with ", format_cheesecoins(tax), " in tax).")
+	for _, user := range []string{wager.Proposer, wager.Counterparty, wager.Arbiter} {
+		if user != "" {
+			send_embed("Wager settled", session, user, result, []*discordgo.MessageEmbedField{})
+		}
+	}
+}
+
+// Records which party a party of a wager says has won. The wager is settled once both parties agree,
+// and if they disagree the arbiter is asked to pick the winner.
+// Returns a description of what happened.
+func vote_wager(session *discordgo.Session, id string, user string, winner string) string {
+	wager := data.Wagers[id]
+	wager.Votes[user] = winner
+
+	other := wager.Proposer
+	if user == wager.Proposer {
+		other = wager.Counterparty
+	}
+	other_vote, voted := wager.Votes[other]
+	switch {
+	case !voted:
+		send_embed("Wager", session, other, fmt.Sprint(format_user(user), " says ", format_user(winner), " won your wager:\n> ", wager.Terms, "\n\nPick the winner from the message with the buttons to settle it."), []*discordgo.MessageEmbedField{})
+		return fmt.Sprint("You said ", format_user(winner), " won. Waiting for ", format_user(other), " to agree.")
+	case other_vote == winner:
+		settle_wager(session, id, winner)
+		return fmt.Sprint("You both agreed that ", format_user(winner), " won. The wager has been settled.")
+	case wager.Arbiter == "":
+		return fmt.Sprint("You said ", format_user(winner), " won but ", format_user(other), " disagrees. There is no arbiter, so the stakes will be refunded <t:", wager.Deadline.Unix(), ":R> unless you agree.")
+	}
+
+	if !wager.Disputed {
+		wager.Disputed = true
+		send_buttons("Wager dispute", session, wager.Arbiter, fmt.Sprint(format_wager(wager), "\n\nThe parties disagree on who won. As the arbiter, pick the winner before <t:", wager.Deadline.Unix(), ":f> or the stakes will be refunded."),
+			wager_winner_buttons("wager_arbitrate", id))
+	}
+	return fmt.Sprint("You said ", format_user(winner), " won but ", format_user(other), " disagrees. ", format_user(wager.Arbiter), " has been asked to pick the winner.")
+}
+
+// Returns the stakes of a wager which has not been settled to the parties
+func refund_wager(session *discordgo.Session, id string, reason string) {
+	wager := data.Wagers[id]
+	delete(data.Wagers, id)
+
+	parties := []string{wager.Proposer}
+	if wager.Accepted {
+		parties = append(parties, wager.Counterparty)
+	}
+	for _, user := range parties {
+		data.Escrow.Balance -= wager.Amount
+		data.PersonalAccounts[data.Users[user].PersonalAccount].Balance += wager.Amount
+	}
+
+	for _, user := range []string{wager.Proposer, wager.Counterparty} {
+		send_embed("Wager refunded", session, user, fmt.Sprint(format_wager(wager), "\n\n", reason, " Any stakes have been refunded."), []*discordgo.MessageEmbedField{})
+	}
+}
+
+// Check every minute for wagers which have not been accepted or decided in time, and refund them
+func check_wagers(session *discordgo.Session) {
+	for range time.Tick(time.Minute * 1) {
+		data_mutex.Lock()
+		for id, wager := range data.Wagers {
+			if !wager.Accepted && time.Now().After(wager.Expires) {
+				refund_wager(session, id, fmt.Sprint(format_user(wager.Counterparty), " did not accept the wager in time."))
+			} else if wager.Accepted && time.Now().After(wager.Deadline) {
+				refund_wager(session, id, "No winner was agreed before the deadline.")
+			}
+		}
+		data_mutex.Unlock()
+	}
+}